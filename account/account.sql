@@ -1,4 +1,12 @@
 CREATE TABLE IF NOT EXISTS accounts (
     id CHAR(27) PRIMARY KEY,
-    name VARCHAR(24) NOT NULL
-);
\ No newline at end of file
+    name VARCHAR(24) NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    account_id CHAR(27) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
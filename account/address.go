@@ -0,0 +1,15 @@
+package account
+
+import (
+	"time"
+
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+)
+
+// Address is an entry in an account's address book.
+type Address struct {
+	ID        string    `json:"id"`
+	AccountID string    `json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+	address.Address
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS addresses (
+    id CHAR(27) PRIMARY KEY,
+    account_id CHAR(27) NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    line1 VARCHAR(255) NOT NULL,
+    line2 VARCHAR(255) NOT NULL DEFAULT '',
+    city VARCHAR(255) NOT NULL,
+    region VARCHAR(64) NOT NULL DEFAULT '',
+    postal_code VARCHAR(32) NOT NULL,
+    country CHAR(2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS addresses_account_id_idx ON addresses (account_id);
@@ -3,8 +3,12 @@ package account
 import (
 	"context"
 	"log"
+	"time"
 
 	pb "github.com/stiffinWanjohi/go-ecommerce/account/pb"
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
+	"github.com/stiffinWanjohi/go-ecommerce/grpcerr"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -14,10 +18,14 @@ type Client struct {
 	service pb.AccountServiceClient
 }
 
-func NewClient(url string) (*Client, error) {
+func NewClient(url string, id *auth.ServiceIdentity) (*Client, error) {
 	conn, err := grpc.NewClient(
 		url,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(
+			auth.UnaryClientInterceptor(id),
+			grpcerr.UnaryClientInterceptor(reasons),
+		),
 	)
 	if err != nil {
 		return nil, err
@@ -35,21 +43,20 @@ func (c *Client) Close() {
 func (c *Client) PostAccount(
 	ctx context.Context,
 	name string,
+	idempotencyKey string,
 ) (*Account, error) {
 	r, err := c.service.PostAccount(
 		ctx,
 		&pb.PostAccountRequest{
-			Name: name,
+			Name:           name,
+			IdempotencyKey: idempotencyKey,
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Account{
-		ID:   r.Account.Id,
-		Name: r.Account.Name,
-	}, nil
+	return accountFromProto(r.Account), nil
 }
 
 func (c *Client) GetAccount(
@@ -66,10 +73,7 @@ func (c *Client) GetAccount(
 		return nil, err
 	}
 
-	return &Account{
-		ID:   r.Account.Id,
-		Name: r.Account.Name,
-	}, nil
+	return accountFromProto(r.Account), nil
 }
 
 func (c *Client) GetAccounts(
@@ -89,13 +93,221 @@ func (c *Client) GetAccounts(
 
 	accounts := []Account{}
 	for _, a := range r.Accounts {
-		accounts = append(accounts,
-			Account{
-				ID:   a.Id,
-				Name: a.Name,
-			},
-		)
+		accounts = append(accounts, *accountFromProto(a))
 	}
 
 	return accounts, nil
 }
+
+func (c *Client) UpdateAccount(
+	ctx context.Context,
+	id string,
+	name string,
+) (*Account, error) {
+	r, err := c.service.UpdateAccount(
+		ctx,
+		&pb.UpdateAccountRequest{
+			Id:   id,
+			Name: name,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return accountFromProto(r.Account), nil
+}
+
+func (c *Client) DeleteAccount(ctx context.Context, id string) error {
+	_, err := c.service.DeleteAccount(
+		ctx,
+		&pb.DeleteAccountRequest{
+			Id: id,
+		},
+	)
+	return err
+}
+
+func (c *Client) PostAddress(
+	ctx context.Context,
+	accountID string,
+	a address.Address,
+) (*Address, error) {
+	r, err := c.service.PostAddress(
+		ctx,
+		&pb.PostAddressRequest{
+			AccountId: accountID,
+			Address:   a.Proto(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return addressFromProto(r.Address), nil
+}
+
+func (c *Client) GetAddresses(
+	ctx context.Context,
+	accountID string,
+) ([]Address, error) {
+	r, err := c.service.GetAddresses(
+		ctx,
+		&pb.GetAddressesRequest{
+			AccountId: accountID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := []Address{}
+	for _, a := range r.Addresses {
+		addresses = append(addresses, *addressFromProto(a))
+	}
+
+	return addresses, nil
+}
+
+func (c *Client) GetAddress(
+	ctx context.Context,
+	accountID string,
+	id string,
+) (*Address, error) {
+	r, err := c.service.GetAddress(
+		ctx,
+		&pb.GetAddressRequest{
+			AccountId: accountID,
+			Id:        id,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return addressFromProto(r.Address), nil
+}
+
+func (c *Client) UpdateAddress(
+	ctx context.Context,
+	accountID string,
+	id string,
+	a address.Address,
+) (*Address, error) {
+	r, err := c.service.UpdateAddress(
+		ctx,
+		&pb.UpdateAddressRequest{
+			AccountId: accountID,
+			Id:        id,
+			Address:   a.Proto(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return addressFromProto(r.Address), nil
+}
+
+func (c *Client) DeleteAddress(
+	ctx context.Context,
+	accountID string,
+	id string,
+) error {
+	_, err := c.service.DeleteAddress(
+		ctx,
+		&pb.DeleteAddressRequest{
+			AccountId: accountID,
+			Id:        id,
+		},
+	)
+	return err
+}
+
+func (c *Client) Register(
+	ctx context.Context,
+	name string,
+	email string,
+	password string,
+) (*Session, error) {
+	r, err := c.service.Register(
+		ctx,
+		&pb.RegisterRequest{
+			Name:     name,
+			Email:    email,
+			Password: password,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionFromProto(r.Session), nil
+}
+
+func (c *Client) Login(
+	ctx context.Context,
+	email string,
+	password string,
+) (*Session, error) {
+	r, err := c.service.Login(
+		ctx,
+		&pb.LoginRequest{
+			Email:    email,
+			Password: password,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionFromProto(r.Session), nil
+}
+
+func (c *Client) ChangePassword(
+	ctx context.Context,
+	accountID string,
+	current string,
+	password string,
+) error {
+	_, err := c.service.ChangePassword(
+		ctx,
+		&pb.ChangePasswordRequest{
+			AccountId:       accountID,
+			CurrentPassword: current,
+			NewPassword:     password,
+		},
+	)
+	return err
+}
+
+func accountFromProto(ap *pb.Account) *Account {
+	a := &Account{
+		ID:   ap.Id,
+		Name: ap.Name,
+	}
+	if len(ap.DeletedAt) > 0 {
+		a.DeletedAt = &time.Time{}
+		a.DeletedAt.UnmarshalBinary(ap.DeletedAt)
+	}
+	return a
+}
+
+func addressFromProto(ap *pb.AccountAddress) *Address {
+	a := &Address{
+		ID:        ap.Id,
+		AccountID: ap.AccountId,
+		Address:   address.FromProto(ap.Address),
+	}
+	a.CreatedAt.UnmarshalBinary(ap.CreatedAt)
+	return a
+}
+
+func sessionFromProto(sp *pb.Session) *Session {
+	s := &Session{
+		Account: *accountFromProto(sp.Account),
+		Token:   sp.Token,
+	}
+	s.ExpiresAt.UnmarshalBinary(sp.ExpiresAt)
+	return s
+}
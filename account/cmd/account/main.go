@@ -6,11 +6,14 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/stiffinWanjohi/go-ecommerce/account"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
 	"github.com/tinrab/retry"
 )
 
 type Config struct {
-	DatabaseURL string `envconfig:"DATABASE_URL"`
+	DatabaseURL   string        `envconfig:"DATABASE_URL"`
+	SessionSecret string        `envconfig:"SESSION_SECRET" required:"true"`
+	SessionTTL    time.Duration `envconfig:"SESSION_TTL" default:"24h"`
 }
 
 func main() {
@@ -31,6 +34,7 @@ func main() {
 	defer r.Close()
 
 	log.Println("Listening on port 8001...")
-	s := account.NewAccountService(r)
-	log.Fatal(account.ListenGRPC(s, 8001))
+	signer := auth.NewSigner(cfg.SessionSecret, cfg.SessionTTL)
+	s := account.NewAccountService(r, signer)
+	log.Fatal(account.ListenGRPC(s, signer, 8001))
 }
@@ -0,0 +1,132 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 128
+)
+
+var (
+	ErrEmailTaken         = errors.New("email already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
+// argon2id parameters for new hashes. Stored hashes carry their own, so
+// these can be raised without invalidating existing passwords.
+const (
+	argonTime    = 1
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	argonKeyLen  = 32
+	argonSaltLen = 16
+)
+
+type (
+	// Credentials let an account log in. Each account has at most one
+	// email, and each email belongs to at most one account.
+	Credentials struct {
+		AccountID    string    `json:"account_id"`
+		Email        string    `json:"email"`
+		PasswordHash string    `json:"-"`
+		Role         string    `json:"role"`
+		CreatedAt    time.Time `json:"created_at"`
+		UpdatedAt    time.Time `json:"updated_at"`
+	}
+
+	// Session is what a successful registration or login returns.
+	Session struct {
+		Account   Account
+		Token     string
+		ExpiresAt time.Time
+	}
+)
+
+// normalizeEmail lower-cases email and reports whether it is a plain
+// address that fits the credentials table.
+func normalizeEmail(email string) (string, bool) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	a, err := mail.ParseAddress(email)
+	return email, err == nil && a.Address == email && len(email) <= 254
+}
+
+func checkPassword(v *validation.Error, field string, password string) {
+	n := len([]rune(password))
+	v.Check(
+		n >= MinPasswordLength && n <= MaxPasswordLength,
+		field,
+		"must be %d to %d characters",
+		MinPasswordLength,
+		MaxPasswordLength,
+	)
+}
+
+// hashPassword derives an argon2id hash of password with a random salt and
+// encodes it in the PHC string format.
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		argonMemory,
+		argonTime,
+		argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// verifyPassword reports whether password matches an encoded hash made by
+// hashPassword.
+func verifyPassword(encoded string, password string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, errors.New("unsupported password hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, err
+	}
+	if version != argon2.Version {
+		return false, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+
+	var (
+		memory     uint32
+		iterations uint32
+		threads    uint8
+	)
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS credentials (
+    account_id CHAR(27) PRIMARY KEY REFERENCES accounts (id) ON DELETE CASCADE,
+    email VARCHAR(254) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    role VARCHAR(32) NOT NULL DEFAULT 'customer',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,7 @@
+-- Remembers the account created for a client supplied idempotency key.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    account_id CHAR(27) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,2 @@
+-- Deleted accounts are kept with the time they were deleted.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
@@ -3,8 +3,16 @@ package account
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/stiffinWanjohi/go-ecommerce/idempotency"
+)
+
+var (
+	ErrNotFound              = errors.New("entity not found")
+	ErrIdempotencyKeyClaimed = errors.New("idempotency key already claimed")
 )
 
 type (
@@ -13,6 +21,18 @@ type (
 		PutAccount(ctx context.Context, a Account) error
 		GetAccountById(ctx context.Context, id string) (*Account, error)
 		ListAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error)
+		UpdateAccount(ctx context.Context, a Account) error
+		DeleteAccount(ctx context.Context, id string, deletedAt time.Time) error
+		GetIdempotencyKey(ctx context.Context, key string) (accountID string, fingerprint string, err error)
+		PutAddress(ctx context.Context, a Address) error
+		GetAddresses(ctx context.Context, accountID string) ([]Address, error)
+		GetAddress(ctx context.Context, accountID string, id string) (*Address, error)
+		UpdateAddress(ctx context.Context, a Address) error
+		DeleteAddress(ctx context.Context, accountID string, id string) error
+		PutAccountWithCredentials(ctx context.Context, a Account, c Credentials) error
+		GetCredentials(ctx context.Context, accountID string) (*Credentials, error)
+		GetCredentialsByEmail(ctx context.Context, email string) (*Credentials, error)
+		UpdatePasswordHash(ctx context.Context, accountID string, hash string, updatedAt time.Time) error
 	}
 
 	postgresRepository struct {
@@ -45,18 +65,83 @@ func (r *postgresRepository) Ping() error {
 func (r *postgresRepository) PutAccount(
 	ctx context.Context,
 	a Account,
-) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO accounts(id, name) VALUES($1, $2)", a.ID, a.Name)
+) (err error) {
+	if a.IdempotencyKey == "" {
+		_, err = r.db.ExecContext(ctx, "INSERT INTO accounts(id, name) VALUES($1, $2)", a.ID, a.Name)
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Claim the key, taking over an expired one
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO idempotency_keys(key, fingerprint, account_id, expires_at) 
+		VALUES($1, $2, $3, $4) 
+		ON CONFLICT (key) DO UPDATE SET 
+		fingerprint = EXCLUDED.fingerprint, 
+		account_id = EXCLUDED.account_id, 
+		expires_at = EXCLUDED.expires_at 
+		WHERE idempotency_keys.expires_at < NOW()`,
+		a.IdempotencyKey, a.Fingerprint(), a.ID, time.Now().Add(idempotency.TTL),
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrIdempotencyKeyClaimed
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO accounts(id, name) VALUES($1, $2)", a.ID, a.Name)
 	return err
 }
 
+func (r *postgresRepository) GetIdempotencyKey(
+	ctx context.Context,
+	key string,
+) (string, string, error) {
+	var accountID, fingerprint string
+	row := r.db.QueryRowContext(
+		ctx,
+		"SELECT account_id, fingerprint FROM idempotency_keys WHERE key = $1 AND expires_at >= NOW()",
+		key,
+	)
+	if err := row.Scan(&accountID, &fingerprint); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", ErrNotFound
+		}
+		return "", "", err
+	}
+
+	return accountID, fingerprint, nil
+}
+
 func (r *postgresRepository) GetAccountById(
 	ctx context.Context,
 	id string,
 ) (*Account, error) {
 	a := &Account{}
-	row := r.db.QueryRowContext(ctx, "SELECT id, name FROM accounts WHERE id = $1", id)
-	if err := row.Scan(&a.ID, &a.Name); err != nil {
+	row := r.db.QueryRowContext(ctx, "SELECT id, name, deleted_at FROM accounts WHERE id = $1", id)
+	if err := row.Scan(&a.ID, &a.Name, &a.DeletedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
 
@@ -72,7 +157,7 @@ func (r *postgresRepository) ListAccounts(
 
 	rows, err := r.db.QueryContext(
 		ctx,
-		"SELECT id, name FROM accounts ORDER BY id DESC OFFSET $1 LIMIT $2",
+		"SELECT id, name FROM accounts WHERE deleted_at IS NULL ORDER BY id DESC OFFSET $1 LIMIT $2",
 		skip,
 		take,
 	)
@@ -96,3 +181,260 @@ func (r *postgresRepository) ListAccounts(
 
 	return accounts, nil
 }
+
+// UpdateAccount renames an account that has not been deleted.
+func (r *postgresRepository) UpdateAccount(
+	ctx context.Context,
+	a Account,
+) error {
+	res, err := r.db.ExecContext(
+		ctx,
+		"UPDATE accounts SET name = $1 WHERE id = $2 AND deleted_at IS NULL",
+		a.Name, a.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return expectRow(res)
+}
+
+func (r *postgresRepository) DeleteAccount(
+	ctx context.Context,
+	id string,
+	deletedAt time.Time,
+) error {
+	res, err := r.db.ExecContext(
+		ctx,
+		"UPDATE accounts SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL",
+		deletedAt, id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectRow(res)
+}
+
+func (r *postgresRepository) PutAddress(
+	ctx context.Context,
+	a Address,
+) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO addresses(id, account_id, name, line1, line2, city, region, postal_code, country, created_at) 
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		a.ID, a.AccountID, a.Name, a.Line1, a.Line2, a.City, a.Region, a.PostalCode, a.Country, a.CreatedAt,
+	)
+	return err
+}
+
+func (r *postgresRepository) GetAddresses(
+	ctx context.Context,
+	accountID string,
+) ([]Address, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, account_id, name, line1, line2, city, region, postal_code, country, created_at 
+		FROM addresses WHERE account_id = $1 ORDER BY created_at, id`,
+		accountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := []Address{}
+	for rows.Next() {
+		a, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, *a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+func (r *postgresRepository) GetAddress(
+	ctx context.Context,
+	accountID string,
+	id string,
+) (*Address, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, account_id, name, line1, line2, city, region, postal_code, country, created_at 
+		FROM addresses WHERE account_id = $1 AND id = $2`,
+		accountID, id,
+	)
+	a, err := scanAddress(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return a, err
+}
+
+func (r *postgresRepository) UpdateAddress(
+	ctx context.Context,
+	a Address,
+) error {
+	res, err := r.db.ExecContext(
+		ctx,
+		`UPDATE addresses SET name = $1, line1 = $2, line2 = $3, city = $4, region = $5, postal_code = $6, country = $7 
+		WHERE account_id = $8 AND id = $9`,
+		a.Name, a.Line1, a.Line2, a.City, a.Region, a.PostalCode, a.Country, a.AccountID, a.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return expectRow(res)
+}
+
+func (r *postgresRepository) DeleteAddress(
+	ctx context.Context,
+	accountID string,
+	id string,
+) error {
+	res, err := r.db.ExecContext(
+		ctx,
+		"DELETE FROM addresses WHERE account_id = $1 AND id = $2",
+		accountID, id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectRow(res)
+}
+
+// PutAccountWithCredentials creates an account together with its login,
+// failing with ErrEmailTaken if the email is already registered.
+func (r *postgresRepository) PutAccountWithCredentials(
+	ctx context.Context,
+	a Account,
+	c Credentials,
+) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO accounts(id, name) VALUES($1, $2)", a.ID, a.Name)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO credentials(account_id, email, password_hash, role, created_at, updated_at) 
+		VALUES($1, $2, $3, $4, $5, $6)`,
+		c.AccountID, c.Email, c.PasswordHash, c.Role, c.CreatedAt, c.UpdatedAt,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrEmailTaken
+	}
+	return err
+}
+
+func (r *postgresRepository) GetCredentials(
+	ctx context.Context,
+	accountID string,
+) (*Credentials, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT account_id, email, password_hash, role, created_at, updated_at 
+		FROM credentials WHERE account_id = $1`,
+		accountID,
+	)
+	return scanCredentials(row)
+}
+
+func (r *postgresRepository) GetCredentialsByEmail(
+	ctx context.Context,
+	email string,
+) (*Credentials, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT account_id, email, password_hash, role, created_at, updated_at 
+		FROM credentials WHERE email = $1`,
+		email,
+	)
+	return scanCredentials(row)
+}
+
+func (r *postgresRepository) UpdatePasswordHash(
+	ctx context.Context,
+	accountID string,
+	hash string,
+	updatedAt time.Time,
+) error {
+	res, err := r.db.ExecContext(
+		ctx,
+		"UPDATE credentials SET password_hash = $1, updated_at = $2 WHERE account_id = $3",
+		hash, updatedAt, accountID,
+	)
+	if err != nil {
+		return err
+	}
+	return expectRow(res)
+}
+
+func scanCredentials(row *sql.Row) (*Credentials, error) {
+	c := &Credentials{}
+	if err := row.Scan(
+		&c.AccountID,
+		&c.Email,
+		&c.PasswordHash,
+		&c.Role,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func scanAddress(row interface{ Scan(...any) error }) (*Address, error) {
+	a := &Address{}
+	if err := row.Scan(
+		&a.ID,
+		&a.AccountID,
+		&a.Name,
+		&a.Line1,
+		&a.Line2,
+		&a.City,
+		&a.Region,
+		&a.PostalCode,
+		&a.Country,
+		&a.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// expectRow turns an update or delete that matched nothing into
+// ErrNotFound.
+func expectRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
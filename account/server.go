@@ -2,26 +2,70 @@ package account
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 
 	pb "github.com/stiffinWanjohi/go-ecommerce/account/pb"
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
+	"github.com/stiffinWanjohi/go-ecommerce/grpcerr"
+	"github.com/stiffinWanjohi/go-ecommerce/idempotency"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
 )
 
+const (
+	reasonAccountNotFound     = "ACCOUNT_NOT_FOUND"
+	reasonAddressNotFound     = "ADDRESS_NOT_FOUND"
+	reasonCredentialsNotFound = "CREDENTIALS_NOT_FOUND"
+	reasonEmailTaken          = "EMAIL_TAKEN"
+	reasonInvalidCredentials  = "INVALID_CREDENTIALS"
+)
+
+// reasons maps the reasons the server reports back to the errors of this
+// package.
+var reasons = grpcerr.Reasons{
+	reasonAccountNotFound:      ErrNotFound,
+	reasonAddressNotFound:      ErrNotFound,
+	reasonCredentialsNotFound:  ErrNotFound,
+	reasonEmailTaken:           ErrEmailTaken,
+	reasonInvalidCredentials:   ErrInvalidCredentials,
+	idempotency.ReasonConflict: idempotency.ErrConflict,
+}
+
 type grpcServer struct {
 	pb.UnimplementedAccountServiceServer
 	accountService AccountService
 }
 
-func ListenGRPC(s AccountService, port int) error {
+var policy = auth.Policy{
+	pb.AccountService_PostAccount_FullMethodName:    auth.Public,
+	pb.AccountService_GetAccount_FullMethodName:     auth.Owner((*pb.GetAccountRequest).GetId),
+	pb.AccountService_GetAccounts_FullMethodName:    auth.RequireRole(auth.RoleAdmin),
+	pb.AccountService_UpdateAccount_FullMethodName:  auth.Owner((*pb.UpdateAccountRequest).GetId),
+	pb.AccountService_DeleteAccount_FullMethodName:  auth.Owner((*pb.DeleteAccountRequest).GetId),
+	pb.AccountService_PostAddress_FullMethodName:    auth.Owner((*pb.PostAddressRequest).GetAccountId),
+	pb.AccountService_GetAddresses_FullMethodName:   auth.Owner((*pb.GetAddressesRequest).GetAccountId),
+	pb.AccountService_GetAddress_FullMethodName:     auth.Owner((*pb.GetAddressRequest).GetAccountId),
+	pb.AccountService_UpdateAddress_FullMethodName:  auth.Owner((*pb.UpdateAddressRequest).GetAccountId),
+	pb.AccountService_DeleteAddress_FullMethodName:  auth.Owner((*pb.DeleteAddressRequest).GetAccountId),
+	pb.AccountService_Register_FullMethodName:       auth.Public,
+	pb.AccountService_Login_FullMethodName:          auth.Public,
+	pb.AccountService_ChangePassword_FullMethodName: auth.Owner((*pb.ChangePasswordRequest).GetAccountId),
+}
+
+func ListenGRPC(s AccountService, signer *auth.Signer, port int) error {
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return err
 	}
 
-	serv := grpc.NewServer()
+	serv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcerr.UnaryServerInterceptor(),
+		auth.UnaryServerInterceptor(signer, policy),
+	))
 	pb.RegisterAccountServiceServer(serv, &grpcServer{
 		accountService: s,
 	})
@@ -33,16 +77,13 @@ func (s *grpcServer) PostAccount(
 	ctx context.Context,
 	r *pb.PostAccountRequest,
 ) (*pb.PostAccountResponse, error) {
-	account, err := s.accountService.PostAccount(ctx, r.Name)
+	account, err := s.accountService.PostAccount(ctx, r.Name, r.IdempotencyKey)
 	if err != nil {
-		return nil, err
+		return nil, idempotencyError(err)
 	}
 
 	return &pb.PostAccountResponse{
-		Account: &pb.Account{
-			Id:   account.ID,
-			Name: account.Name,
-		},
+		Account: accountToProto(account),
 	}, nil
 }
 
@@ -52,14 +93,11 @@ func (s *grpcServer) GetAccount(
 ) (*pb.GetAccountResponse, error) {
 	account, err := s.accountService.GetAccount(ctx, r.Id)
 	if err != nil {
-		return nil, err
+		return nil, accountError(r.Id, err)
 	}
 
 	return &pb.GetAccountResponse{
-		Account: &pb.Account{
-			Id:   account.ID,
-			Name: account.Name,
-		},
+		Account: accountToProto(account),
 	}, nil
 }
 
@@ -74,15 +112,201 @@ func (s *grpcServer) GetAccounts(
 
 	accounts := []*pb.Account{}
 	for _, p := range res {
-		accounts = append(accounts,
-			&pb.Account{
-				Id:   p.ID,
-				Name: p.Name,
-			},
-		)
+		accounts = append(accounts, accountToProto(&p))
 	}
 
 	return &pb.GetAccountsResponse{
 		Accounts: accounts,
 	}, nil
 }
+
+func (s *grpcServer) UpdateAccount(
+	ctx context.Context,
+	r *pb.UpdateAccountRequest,
+) (*pb.UpdateAccountResponse, error) {
+	account, err := s.accountService.UpdateAccount(ctx, r.Id, r.Name)
+	if err != nil {
+		return nil, accountError(r.Id, err)
+	}
+
+	return &pb.UpdateAccountResponse{
+		Account: accountToProto(account),
+	}, nil
+}
+
+func (s *grpcServer) DeleteAccount(
+	ctx context.Context,
+	r *pb.DeleteAccountRequest,
+) (*pb.DeleteAccountResponse, error) {
+	if err := s.accountService.DeleteAccount(ctx, r.Id); err != nil {
+		return nil, accountError(r.Id, err)
+	}
+
+	return &pb.DeleteAccountResponse{}, nil
+}
+
+func (s *grpcServer) PostAddress(
+	ctx context.Context,
+	r *pb.PostAddressRequest,
+) (*pb.PostAddressResponse, error) {
+	a, err := s.accountService.PostAddress(ctx, r.AccountId, address.FromProto(r.Address))
+	if errors.Is(err, ErrNotFound) {
+		return nil, accountError(r.AccountId, err)
+	}
+	if err != nil {
+		return nil, addressError("", err)
+	}
+
+	return &pb.PostAddressResponse{Address: addressToProto(a)}, nil
+}
+
+func (s *grpcServer) GetAddresses(
+	ctx context.Context,
+	r *pb.GetAddressesRequest,
+) (*pb.GetAddressesResponse, error) {
+	res, err := s.accountService.GetAddresses(ctx, r.AccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := []*pb.AccountAddress{}
+	for _, a := range res {
+		addresses = append(addresses, addressToProto(&a))
+	}
+
+	return &pb.GetAddressesResponse{Addresses: addresses}, nil
+}
+
+func (s *grpcServer) GetAddress(
+	ctx context.Context,
+	r *pb.GetAddressRequest,
+) (*pb.GetAddressResponse, error) {
+	a, err := s.accountService.GetAddress(ctx, r.AccountId, r.Id)
+	if err != nil {
+		return nil, addressError(r.Id, err)
+	}
+
+	return &pb.GetAddressResponse{Address: addressToProto(a)}, nil
+}
+
+func (s *grpcServer) UpdateAddress(
+	ctx context.Context,
+	r *pb.UpdateAddressRequest,
+) (*pb.UpdateAddressResponse, error) {
+	a, err := s.accountService.UpdateAddress(ctx, r.AccountId, r.Id, address.FromProto(r.Address))
+	if err != nil {
+		return nil, addressError(r.Id, err)
+	}
+
+	return &pb.UpdateAddressResponse{Address: addressToProto(a)}, nil
+}
+
+func (s *grpcServer) DeleteAddress(
+	ctx context.Context,
+	r *pb.DeleteAddressRequest,
+) (*pb.DeleteAddressResponse, error) {
+	if err := s.accountService.DeleteAddress(ctx, r.AccountId, r.Id); err != nil {
+		return nil, addressError(r.Id, err)
+	}
+
+	return &pb.DeleteAddressResponse{}, nil
+}
+
+func (s *grpcServer) Register(
+	ctx context.Context,
+	r *pb.RegisterRequest,
+) (*pb.RegisterResponse, error) {
+	session, err := s.accountService.Register(ctx, r.Name, r.Email, r.Password)
+	if err != nil {
+		return nil, credentialsError(err)
+	}
+
+	return &pb.RegisterResponse{Session: sessionToProto(session)}, nil
+}
+
+func (s *grpcServer) Login(
+	ctx context.Context,
+	r *pb.LoginRequest,
+) (*pb.LoginResponse, error) {
+	session, err := s.accountService.Login(ctx, r.Email, r.Password)
+	if err != nil {
+		return nil, credentialsError(err)
+	}
+
+	return &pb.LoginResponse{Session: sessionToProto(session)}, nil
+}
+
+func (s *grpcServer) ChangePassword(
+	ctx context.Context,
+	r *pb.ChangePasswordRequest,
+) (*pb.ChangePasswordResponse, error) {
+	err := s.accountService.ChangePassword(ctx, r.AccountId, r.CurrentPassword, r.NewPassword)
+	if err != nil {
+		return nil, credentialsError(err)
+	}
+
+	return &pb.ChangePasswordResponse{}, nil
+}
+
+func accountError(id string, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return grpcerr.New(codes.NotFound, reasonAccountNotFound, fmt.Sprintf("account %s not found", id))
+	}
+	return err
+}
+
+func idempotencyError(err error) error {
+	if errors.Is(err, idempotency.ErrConflict) {
+		return grpcerr.New(codes.AlreadyExists, idempotency.ReasonConflict, err.Error())
+	}
+	return err
+}
+
+func accountToProto(a *Account) *pb.Account {
+	ap := &pb.Account{
+		Id:   a.ID,
+		Name: a.Name,
+	}
+	if a.DeletedAt != nil {
+		ap.DeletedAt, _ = a.DeletedAt.MarshalBinary()
+	}
+	return ap
+}
+
+func addressError(id string, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return grpcerr.New(codes.NotFound, reasonAddressNotFound, fmt.Sprintf("address %s not found", id))
+	}
+	return err
+}
+
+func credentialsError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return grpcerr.New(codes.NotFound, reasonCredentialsNotFound, "credentials not found")
+	case errors.Is(err, ErrEmailTaken):
+		return grpcerr.New(codes.AlreadyExists, reasonEmailTaken, err.Error())
+	case errors.Is(err, ErrInvalidCredentials):
+		return grpcerr.New(codes.Unauthenticated, reasonInvalidCredentials, err.Error())
+	}
+	return err
+}
+
+func sessionToProto(s *Session) *pb.Session {
+	sp := &pb.Session{
+		Account: accountToProto(&s.Account),
+		Token:   s.Token,
+	}
+	sp.ExpiresAt, _ = s.ExpiresAt.MarshalBinary()
+	return sp
+}
+
+func addressToProto(a *Address) *pb.AccountAddress {
+	ap := &pb.AccountAddress{
+		Id:        a.ID,
+		AccountId: a.AccountID,
+		Address:   a.Address.Proto(),
+	}
+	ap.CreatedAt, _ = a.CreatedAt.MarshalBinary()
+	return ap
+}
@@ -2,41 +2,94 @@ package account
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/segmentio/ksuid"
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
+	"github.com/stiffinWanjohi/go-ecommerce/idempotency"
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
 )
 
 type (
 	AccountService interface {
-		PostAccount(ctx context.Context, name string) (*Account, error)
+		PostAccount(ctx context.Context, name string, idempotencyKey string) (*Account, error)
 		GetAccount(ctx context.Context, id string) (*Account, error)
 		GetAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error)
+		UpdateAccount(ctx context.Context, id string, name string) (*Account, error)
+		DeleteAccount(ctx context.Context, id string) error
+		PostAddress(ctx context.Context, accountID string, a address.Address) (*Address, error)
+		GetAddresses(ctx context.Context, accountID string) ([]Address, error)
+		GetAddress(ctx context.Context, accountID string, id string) (*Address, error)
+		UpdateAddress(ctx context.Context, accountID string, id string, a address.Address) (*Address, error)
+		DeleteAddress(ctx context.Context, accountID string, id string) error
+		Register(ctx context.Context, name string, email string, password string) (*Session, error)
+		Login(ctx context.Context, email string, password string) (*Session, error)
+		ChangePassword(ctx context.Context, accountID string, current string, password string) error
 	}
 
 	accountService struct {
 		repository AccountRepository
+		signer     *auth.Signer
 	}
 
 	Account struct {
 		ID   string `json:"id"`
 		Name string `json:"name"`
+
+		// DeletedAt is set once the account is closed. Deleted accounts
+		// are kept for the orders that reference them.
+		DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+		// IdempotencyKey is the optional client supplied key the account
+		// was created with. It is not part of the stored account.
+		IdempotencyKey string `json:"-"`
 	}
 )
 
-func NewAccountService(r AccountRepository) AccountService {
-	return &accountService{r}
+// MaxNameLength is the size of the accounts.name column.
+const MaxNameLength = 24
+
+func (a Account) Validate() error {
+	v := &validation.Error{}
+	v.Check(validation.Length(a.Name, 1, MaxNameLength), "name", "is required and must be at most %d characters", MaxNameLength)
+	return v.Err()
+}
+
+func (a Account) Fingerprint() string {
+	return idempotency.Fingerprint(a.Name)
+}
+
+func NewAccountService(r AccountRepository, signer *auth.Signer) AccountService {
+	return &accountService{r, signer}
 }
 
 func (s *accountService) PostAccount(
 	ctx context.Context,
 	name string,
+	idempotencyKey string,
 ) (*Account, error) {
 	account := Account{
-		ID:   ksuid.New().String(),
-		Name: name,
+		ID:             ksuid.New().String(),
+		Name:           strings.TrimSpace(name),
+		IdempotencyKey: idempotencyKey,
+	}
+	if err := account.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Replay the original account if this request was already handled
+	replayed, err := s.getIdempotentAccount(ctx, account)
+	if err != nil || replayed != nil {
+		return replayed, err
 	}
 
-	err := s.repository.PutAccount(ctx, account)
+	err = s.repository.PutAccount(ctx, account)
+	if errors.Is(err, ErrIdempotencyKeyClaimed) {
+		return s.getIdempotentAccount(ctx, account)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +97,29 @@ func (s *accountService) PostAccount(
 	return &account, nil
 }
 
+func (s *accountService) getIdempotentAccount(
+	ctx context.Context,
+	a Account,
+) (*Account, error) {
+	if a.IdempotencyKey == "" {
+		return nil, nil
+	}
+
+	accountID, fingerprint, err := s.repository.GetIdempotencyKey(ctx, a.IdempotencyKey)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if fingerprint != a.Fingerprint() {
+		return nil, idempotency.ErrConflict
+	}
+
+	return s.repository.GetAccountById(ctx, accountID)
+}
+
 func (s *accountService) GetAccount(
 	ctx context.Context,
 	id string,
@@ -61,3 +137,239 @@ func (s *accountService) GetAccounts(
 	}
 	return s.repository.ListAccounts(ctx, skip, take)
 }
+
+func (s *accountService) UpdateAccount(
+	ctx context.Context,
+	id string,
+	name string,
+) (*Account, error) {
+	account := Account{
+		ID:   id,
+		Name: strings.TrimSpace(name),
+	}
+	if err := account.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repository.UpdateAccount(ctx, account); err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (s *accountService) DeleteAccount(
+	ctx context.Context,
+	id string,
+) error {
+	return s.repository.DeleteAccount(ctx, id, time.Now())
+}
+
+func (s *accountService) PostAddress(
+	ctx context.Context,
+	accountID string,
+	a address.Address,
+) (*Address, error) {
+	a = a.Normalize()
+	v := &validation.Error{}
+	v.Merge("address", a.Validate())
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	account, err := s.repository.GetAccountById(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+
+	entry := Address{
+		ID:        ksuid.New().String(),
+		AccountID: accountID,
+		CreatedAt: time.Now(),
+		Address:   a,
+	}
+	if err := s.repository.PutAddress(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (s *accountService) GetAddresses(
+	ctx context.Context,
+	accountID string,
+) ([]Address, error) {
+	return s.repository.GetAddresses(ctx, accountID)
+}
+
+func (s *accountService) GetAddress(
+	ctx context.Context,
+	accountID string,
+	id string,
+) (*Address, error) {
+	return s.repository.GetAddress(ctx, accountID, id)
+}
+
+func (s *accountService) UpdateAddress(
+	ctx context.Context,
+	accountID string,
+	id string,
+	a address.Address,
+) (*Address, error) {
+	a = a.Normalize()
+	v := &validation.Error{}
+	v.Merge("address", a.Validate())
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.repository.GetAddress(ctx, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Address = a
+	if err := s.repository.UpdateAddress(ctx, *entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (s *accountService) DeleteAddress(
+	ctx context.Context,
+	accountID string,
+	id string,
+) error {
+	return s.repository.DeleteAddress(ctx, accountID, id)
+}
+
+func (s *accountService) Register(
+	ctx context.Context,
+	name string,
+	email string,
+	password string,
+) (*Session, error) {
+	account := Account{
+		ID:   ksuid.New().String(),
+		Name: strings.TrimSpace(name),
+	}
+	email, ok := normalizeEmail(email)
+
+	v := &validation.Error{}
+	v.Merge("", account.Validate())
+	v.Check(ok, "email", "must be a valid email address")
+	checkPassword(v, "password", password)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	credentials := Credentials{
+		AccountID:    account.ID,
+		Email:        email,
+		PasswordHash: hash,
+		Role:         auth.RoleCustomer,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.repository.PutAccountWithCredentials(ctx, account, credentials); err != nil {
+		return nil, err
+	}
+
+	return s.newSession(account, credentials)
+}
+
+func (s *accountService) Login(
+	ctx context.Context,
+	email string,
+	password string,
+) (*Session, error) {
+	email, ok := normalizeEmail(email)
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	credentials, err := s.repository.GetCredentialsByEmail(ctx, email)
+	if errors.Is(err, ErrNotFound) {
+		// Hash anyway so unknown emails take as long as wrong passwords
+		hashPassword(password)
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err = verifyPassword(credentials.PasswordHash, password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	account, err := s.repository.GetAccountById(ctx, credentials.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.DeletedAt != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.newSession(*account, *credentials)
+}
+
+func (s *accountService) ChangePassword(
+	ctx context.Context,
+	accountID string,
+	current string,
+	password string,
+) error {
+	v := &validation.Error{}
+	checkPassword(v, "newPassword", password)
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	credentials, err := s.repository.GetCredentials(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := verifyPassword(credentials.PasswordHash, current)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidCredentials
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.repository.UpdatePasswordHash(ctx, accountID, hash, time.Now())
+}
+
+func (s *accountService) newSession(
+	a Account,
+	c Credentials,
+) (*Session, error) {
+	token, expiresAt, err := s.signer.Sign(a.ID, c.Email, []string{c.Role})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		Account:   a,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
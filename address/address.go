@@ -0,0 +1,72 @@
+package address
+
+import (
+	"strings"
+
+	pb "github.com/stiffinWanjohi/go-ecommerce/address/pb"
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
+)
+
+// Address is a postal address. Country is an ISO 3166-1 alpha-2 code and
+// Region the subdivision within it, e.g. a US state.
+type Address struct {
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// Validate checks that the address has what a carrier needs to deliver to
+// it and fits the columns it is stored in.
+func (a Address) Validate() error {
+	v := &validation.Error{}
+	v.Check(validation.Length(a.Name, 1, 255), "name", "is required and must be at most 255 characters")
+	v.Check(validation.Length(a.Line1, 1, 255), "line1", "is required and must be at most 255 characters")
+	v.Check(validation.Length(a.Line2, 0, 255), "line2", "must be at most 255 characters")
+	v.Check(validation.Length(a.City, 1, 255), "city", "is required and must be at most 255 characters")
+	v.Check(validation.Length(a.Region, 0, 64), "region", "must be at most 64 characters")
+	v.Check(validation.Length(a.PostalCode, 1, 32), "postalCode", "is required and must be at most 32 characters")
+	v.Check(len(strings.TrimSpace(a.Country)) == 2, "country", "must be a two letter code")
+	return v.Err()
+}
+
+// Normalize upper-cases the country and region codes.
+func (a Address) Normalize() Address {
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.Region = strings.ToUpper(strings.TrimSpace(a.Region))
+	return a
+}
+
+func FromProto(p *pb.Address) Address {
+	if p == nil {
+		return Address{}
+	}
+	return Address{
+		Name:       p.Name,
+		Line1:      p.Line1,
+		Line2:      p.Line2,
+		City:       p.City,
+		Region:     p.Region,
+		PostalCode: p.PostalCode,
+		Country:    p.Country,
+	}
+}
+
+func (a Address) Proto() *pb.Address {
+	return &pb.Address{
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
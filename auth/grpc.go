@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const metadataKey = "authorization"
+
+type (
+	// Rule decides whether a caller may make a call with the request. The
+	// principal is nil for anonymous callers.
+	Rule func(p *Principal, req interface{}) bool
+
+	// Policy holds the rule of every method a server exposes, by full
+	// method name. Methods without a rule are denied.
+	Policy map[string]Rule
+
+	// ServiceIdentity signs the tokens a service presents when calling
+	// other services on its own behalf.
+	ServiceIdentity struct {
+		signer *Signer
+		name   string
+	}
+)
+
+func Public(*Principal, interface{}) bool {
+	return true
+}
+
+func Authenticated(p *Principal, _ interface{}) bool {
+	return p != nil
+}
+
+func RequireRole(role string) Rule {
+	return func(p *Principal, _ interface{}) bool {
+		return p != nil && p.HasRole(role)
+	}
+}
+
+// Owner lets through the account whose ID accountID reads from the
+// request, as well as admins and services.
+func Owner[T any](accountID func(T) string) Rule {
+	return func(p *Principal, req interface{}) bool {
+		r, ok := req.(T)
+		return ok && p != nil && p.CanAccess(accountID(r))
+	}
+}
+
+func NewServiceIdentity(signer *Signer, name string) *ServiceIdentity {
+	return &ServiceIdentity{signer, name}
+}
+
+func (id *ServiceIdentity) Token() (string, error) {
+	token, _, err := id.signer.Sign("service:"+id.name, "", []string{RoleService})
+	return token, err
+}
+
+// UnaryClientInterceptor attaches the caller's token to outgoing calls. With
+// a service identity the service's own token is sent instead of the token
+// of the principal in the context.
+func UnaryClientInterceptor(id *ServiceIdentity) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		var token string
+		if id != nil {
+			var err error
+			token, err = id.Token()
+			if err != nil {
+				return err
+			}
+		} else if p, ok := FromContext(ctx); ok {
+			token = p.Token
+		}
+
+		if token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, metadataKey, "Bearer "+token)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryServerInterceptor authenticates incoming calls and enforces the
+// policy. The principal is put into the context of the handler.
+func UnaryServerInterceptor(signer *Signer, policy Policy) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		var principal *Principal
+		if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(metadataKey)) > 0 {
+			token, ok := strings.CutPrefix(md.Get(metadataKey)[0], "Bearer ")
+			if !ok {
+				return nil, status.Error(codes.Unauthenticated, "unsupported authorization scheme")
+			}
+
+			p, err := signer.Authenticate(token)
+			if err != nil {
+				return nil, status.Error(codes.Unauthenticated, ErrInvalidToken.Error())
+			}
+			principal = &p
+			ctx = NewContext(ctx, p)
+		}
+
+		rule, ok := policy[info.FullMethod]
+		if !ok || !rule(principal, req) {
+			if principal == nil && ok {
+				return nil, status.Error(codes.Unauthenticated, "authentication required")
+			}
+			return nil, status.Errorf(codes.PermissionDenied, "not allowed to call %s", info.FullMethod)
+		}
+
+		return handler(ctx, req)
+	}
+}
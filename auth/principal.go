@@ -0,0 +1,48 @@
+package auth
+
+import "context"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	AccountID string
+	Email     string
+	Roles     []string
+
+	// Token is the session token the principal authenticated with, kept
+	// to forward it to downstream services.
+	Token string
+}
+
+type principalKey struct{}
+
+// HasRole reports whether the principal was granted role. Admins hold
+// every role.
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role || r == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Principal) IsAdmin() bool {
+	return p.HasRole(RoleAdmin)
+}
+
+// CanAccess reports whether the principal may act on resources of the
+// account. Admins and services may act on every account.
+func (p Principal) CanAccess(accountID string) bool {
+	return p.HasRole(RoleService) || (accountID != "" && p.AccountID == accountID)
+}
+
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal of the request, if it is
+// authenticated.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS cart_items (
+    account_id CHAR(27) NOT NULL,
+    product_id CHAR(27) NOT NULL,
+    variant_id VARCHAR(27) NOT NULL DEFAULT '',
+    quantity INT NOT NULL CHECK (quantity > 0),
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (account_id, product_id, variant_id)
+);
+
+CREATE TABLE IF NOT EXISTS checkouts (
+    account_id CHAR(27) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    order_id CHAR(27) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (account_id, idempotency_key)
+);
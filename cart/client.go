@@ -0,0 +1,205 @@
+package cart
+
+import (
+	"context"
+	"log"
+
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
+	pb "github.com/stiffinWanjohi/go-ecommerce/cart/pb"
+	"github.com/stiffinWanjohi/go-ecommerce/grpcerr"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type (
+	Client struct {
+		conn    *grpc.ClientConn
+		service pb.CartServiceClient
+	}
+
+	// CheckoutRequest says how the cart of an account is turned into an
+	// order, see order.OrderRequest.
+	CheckoutRequest struct {
+		AccountID         string
+		IdempotencyKey    string
+		CouponCodes       []string
+		ShippingAddress   address.Address
+		ShippingAddressID string
+		BillingAddress    address.Address
+		BillingAddressID  string
+		ShippingMethod    string
+	}
+)
+
+func NewClient(url string, id *auth.ServiceIdentity) (*Client, error) {
+	conn, err := grpc.NewClient(
+		url,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(
+			auth.UnaryClientInterceptor(id),
+			grpcerr.UnaryClientInterceptor(reasons),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c := pb.NewCartServiceClient(conn)
+	log.Printf("Connecting to gRPC server: %s", url)
+	return &Client{conn, c}, nil
+}
+
+func (c *Client) Close() {
+	c.conn.Close()
+}
+
+func (c *Client) GetCart(
+	ctx context.Context,
+	accountID string,
+) (*Cart, error) {
+	r, err := c.service.GetCart(
+		ctx,
+		&pb.GetCartRequest{
+			AccountId: accountID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return cartFromProto(r.Cart), nil
+}
+
+func (c *Client) AddItem(
+	ctx context.Context,
+	accountID string,
+	productID string,
+	variantID string,
+	quantity uint32,
+) (*Cart, error) {
+	r, err := c.service.AddCartItem(
+		ctx,
+		&pb.AddCartItemRequest{
+			AccountId: accountID,
+			ProductId: productID,
+			VariantId: variantID,
+			Quantity:  quantity,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return cartFromProto(r.Cart), nil
+}
+
+func (c *Client) UpdateItem(
+	ctx context.Context,
+	accountID string,
+	productID string,
+	variantID string,
+	quantity uint32,
+) (*Cart, error) {
+	r, err := c.service.UpdateCartItem(
+		ctx,
+		&pb.UpdateCartItemRequest{
+			AccountId: accountID,
+			ProductId: productID,
+			VariantId: variantID,
+			Quantity:  quantity,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return cartFromProto(r.Cart), nil
+}
+
+func (c *Client) RemoveItem(
+	ctx context.Context,
+	accountID string,
+	productID string,
+	variantID string,
+) (*Cart, error) {
+	r, err := c.service.RemoveCartItem(
+		ctx,
+		&pb.RemoveCartItemRequest{
+			AccountId: accountID,
+			ProductId: productID,
+			VariantId: variantID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return cartFromProto(r.Cart), nil
+}
+
+func (c *Client) ClearCart(
+	ctx context.Context,
+	accountID string,
+) (*Cart, error) {
+	r, err := c.service.ClearCart(
+		ctx,
+		&pb.ClearCartRequest{
+			AccountId: accountID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return cartFromProto(r.Cart), nil
+}
+
+func (c *Client) Checkout(
+	ctx context.Context,
+	cr CheckoutRequest,
+) (string, error) {
+	r, err := c.service.Checkout(
+		ctx,
+		&pb.CheckoutRequest{
+			AccountId:         cr.AccountID,
+			IdempotencyKey:    cr.IdempotencyKey,
+			CouponCodes:       cr.CouponCodes,
+			ShippingAddress:   cr.ShippingAddress.Proto(),
+			ShippingAddressId: cr.ShippingAddressID,
+			BillingAddress:    cr.BillingAddress.Proto(),
+			BillingAddressId:  cr.BillingAddressID,
+			ShippingMethod:    cr.ShippingMethod,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return r.OrderId, nil
+}
+
+func cartFromProto(cp *pb.Cart) *Cart {
+	cart := &Cart{
+		AccountID:  cp.AccountId,
+		Items:      []CartItem{},
+		TotalPrice: money.FromProto(cp.TotalPrice),
+	}
+
+	for _, item := range cp.Items {
+		cart.Items = append(cart.Items, CartItem{
+			ProductID:   item.ProductId,
+			VariantID:   item.VariantId,
+			SKU:         item.Sku,
+			VariantName: item.VariantName,
+			Name:        item.Name,
+			Description: item.Description,
+			Price:       money.FromProto(item.Price),
+			Quantity:    item.Quantity,
+			Unavailable: item.Unavailable,
+		})
+	}
+
+	return cart
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
+	"github.com/stiffinWanjohi/go-ecommerce/cart"
+	"github.com/tinrab/retry"
+)
+
+// serviceTokenTTL is how long the tokens this service presents to others
+// are valid. They are signed for every call.
+const serviceTokenTTL = time.Minute
+
+type Config struct {
+	DatabaseURL   string `envconfig:"DATABASE_URL"`
+	SessionSecret string `envconfig:"SESSION_SECRET" required:"true"`
+	CatalogURL    string `envconfig:"CATALOG_SERVICE_URL"`
+	OrderURL      string `envconfig:"ORDER_SERVICE_URL"`
+}
+
+func main() {
+	var cfg Config
+	err := envconfig.Process("", &cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var r cart.CartRepository
+	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
+		r, err = cart.NewPostgresRepository(cfg.DatabaseURL)
+		if err != nil {
+			log.Println(err)
+		}
+		return
+	})
+	defer r.Close()
+
+	log.Println("Listening on port 8001...")
+	s := cart.NewCartService(r)
+	log.Fatal(cart.ListenGRPC(
+		s,
+		cfg.CatalogURL,
+		cfg.OrderURL,
+		auth.NewSigner(cfg.SessionSecret, serviceTokenTTL),
+		8001),
+	)
+}
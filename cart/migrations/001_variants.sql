@@ -0,0 +1,7 @@
+-- Cart lines can be for a variant of a product. Lines without a variant
+-- have an empty variant_id, so that several variants of one product can
+-- be in the cart together.
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS variant_id VARCHAR(27) NOT NULL DEFAULT '';
+
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS cart_items_pkey;
+ALTER TABLE cart_items ADD PRIMARY KEY (account_id, product_id, variant_id);
@@ -0,0 +1,9 @@
+-- Checkouts with an idempotency key remember the order they placed, so
+-- that a retry returns it after the cart was cleared.
+CREATE TABLE IF NOT EXISTS checkouts (
+    account_id CHAR(27) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    order_id CHAR(27) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (account_id, idempotency_key)
+);
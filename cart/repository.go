@@ -0,0 +1,213 @@
+package cart
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stiffinWanjohi/go-ecommerce/idempotency"
+)
+
+var (
+	ErrNotFound = errors.New("entity not found")
+)
+
+type (
+	CartRepository interface {
+		Close()
+		GetCartItems(ctx context.Context, accountID string) ([]CartItem, error)
+		AddCartItem(ctx context.Context, accountID string, productID string, variantID string, quantity uint32) error
+		SetCartItemQuantity(ctx context.Context, accountID string, productID string, variantID string, quantity uint32) error
+		RemoveCartItem(ctx context.Context, accountID string, productID string, variantID string) error
+		ClearCart(ctx context.Context, accountID string) error
+		GetCheckout(ctx context.Context, accountID string, idempotencyKey string) (orderID string, err error)
+		CompleteCheckout(ctx context.Context, accountID string, idempotencyKey string, orderID string) error
+	}
+
+	postgresRepository struct {
+		db *sql.DB
+	}
+)
+
+func NewPostgresRepository(url string) (CartRepository, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+
+	return &postgresRepository{db}, nil
+}
+
+func (r *postgresRepository) Close() {
+	r.db.Close()
+}
+
+func (r *postgresRepository) GetCartItems(
+	ctx context.Context,
+	accountID string,
+) ([]CartItem, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT product_id, variant_id, quantity FROM cart_items WHERE account_id = $1 ORDER BY added_at, product_id, variant_id",
+		accountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []CartItem{}
+	for rows.Next() {
+		item := CartItem{}
+		if err := rows.Scan(&item.ProductID, &item.VariantID, &item.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *postgresRepository) AddCartItem(
+	ctx context.Context,
+	accountID string,
+	productID string,
+	variantID string,
+	quantity uint32,
+) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO cart_items(account_id, product_id, variant_id, quantity, added_at) 
+		VALUES($1, $2, $3, $4, $5) 
+		ON CONFLICT (account_id, product_id, variant_id) 
+		DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity`,
+		accountID, productID, variantID, quantity, time.Now(),
+	)
+	return err
+}
+
+// SetCartItemQuantity changes the quantity of a line already in the cart.
+// Lines are only added by AddCartItem, after the product was checked.
+func (r *postgresRepository) SetCartItemQuantity(
+	ctx context.Context,
+	accountID string,
+	productID string,
+	variantID string,
+	quantity uint32,
+) error {
+	res, err := r.db.ExecContext(
+		ctx,
+		"UPDATE cart_items SET quantity = $1 WHERE account_id = $2 AND product_id = $3 AND variant_id = $4",
+		quantity, accountID, productID, variantID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *postgresRepository) RemoveCartItem(
+	ctx context.Context,
+	accountID string,
+	productID string,
+	variantID string,
+) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"DELETE FROM cart_items WHERE account_id = $1 AND product_id = $2 AND variant_id = $3",
+		accountID, productID, variantID,
+	)
+	return err
+}
+
+func (r *postgresRepository) ClearCart(
+	ctx context.Context,
+	accountID string,
+) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM cart_items WHERE account_id = $1", accountID)
+	return err
+}
+
+// GetCheckout returns the order a checkout with the idempotency key placed,
+// or ErrNotFound if there was none or it expired.
+func (r *postgresRepository) GetCheckout(
+	ctx context.Context,
+	accountID string,
+	idempotencyKey string,
+) (string, error) {
+	var orderID string
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT order_id FROM checkouts 
+		WHERE account_id = $1 AND idempotency_key = $2 AND expires_at >= NOW()`,
+		accountID, idempotencyKey,
+	)
+	if err := row.Scan(&orderID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	return orderID, nil
+}
+
+// CompleteCheckout clears the cart once its order was placed and, with an
+// idempotency key, remembers the order for retries.
+func (r *postgresRepository) CompleteCheckout(
+	ctx context.Context,
+	accountID string,
+	idempotencyKey string,
+	orderID string,
+) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if idempotencyKey != "" {
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO checkouts(account_id, idempotency_key, order_id, expires_at) 
+			VALUES($1, $2, $3, $4) 
+			ON CONFLICT (account_id, idempotency_key) DO UPDATE SET 
+			order_id = EXCLUDED.order_id, 
+			expires_at = EXCLUDED.expires_at`,
+			accountID, idempotencyKey, orderID, time.Now().Add(idempotency.TTL),
+		)
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM cart_items WHERE account_id = $1", accountID)
+	return
+}
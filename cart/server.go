@@ -0,0 +1,411 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
+	pb "github.com/stiffinWanjohi/go-ecommerce/cart/pb"
+	"github.com/stiffinWanjohi/go-ecommerce/catalog"
+	"github.com/stiffinWanjohi/go-ecommerce/grpcerr"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+	"github.com/stiffinWanjohi/go-ecommerce/order"
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+)
+
+const (
+	reasonProductNotFound     = "PRODUCT_NOT_FOUND"
+	reasonCartItemNotFound    = "CART_ITEM_NOT_FOUND"
+	reasonCartItemUnavailable = "CART_ITEM_UNAVAILABLE"
+	reasonEmptyCart           = "EMPTY_CART"
+	reasonMixedCurrencies     = "MIXED_CURRENCIES"
+)
+
+var (
+	ErrEmptyCart       = errors.New("cart is empty")
+	ErrMixedCurrencies = errors.New("cart contains products priced in different currencies")
+	ErrItemUnavailable = errors.New("cart contains products that can no longer be ordered")
+)
+
+// reasons maps the reasons the server reports back to the errors of this
+// package. Errors of the order service placing the order keep their own
+// reasons.
+var reasons = grpcerr.Reasons{
+	reasonCartItemNotFound:    ErrNotFound,
+	reasonCartItemUnavailable: ErrItemUnavailable,
+	reasonEmptyCart:           ErrEmptyCart,
+	reasonMixedCurrencies:     ErrMixedCurrencies,
+}
+
+type grpcServer struct {
+	pb.UnimplementedCartServiceServer
+	cartService   CartService
+	catalogClient *catalog.Client
+	orderClient   *order.Client
+}
+
+var policy = auth.Policy{
+	pb.CartService_GetCart_FullMethodName:        auth.Owner((*pb.GetCartRequest).GetAccountId),
+	pb.CartService_AddCartItem_FullMethodName:    auth.Owner((*pb.AddCartItemRequest).GetAccountId),
+	pb.CartService_UpdateCartItem_FullMethodName: auth.Owner((*pb.UpdateCartItemRequest).GetAccountId),
+	pb.CartService_RemoveCartItem_FullMethodName: auth.Owner((*pb.RemoveCartItemRequest).GetAccountId),
+	pb.CartService_ClearCart_FullMethodName:      auth.Owner((*pb.ClearCartRequest).GetAccountId),
+	pb.CartService_Checkout_FullMethodName:       auth.Owner((*pb.CheckoutRequest).GetAccountId),
+}
+
+func ListenGRPC(
+	s CartService,
+	catalogURL,
+	orderURL string,
+	signer *auth.Signer,
+	port int,
+) error {
+	id := auth.NewServiceIdentity(signer, "cart")
+	catalogClient, err := catalog.NewClient(catalogURL, id)
+	if err != nil {
+		return err
+	}
+
+	orderClient, err := order.NewClient(orderURL, id)
+	if err != nil {
+		catalogClient.Close()
+		return err
+	}
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		catalogClient.Close()
+		orderClient.Close()
+		return err
+	}
+
+	serv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcerr.UnaryServerInterceptor(),
+		auth.UnaryServerInterceptor(signer, policy),
+	))
+	pb.RegisterCartServiceServer(serv, &grpcServer{
+		cartService:   s,
+		catalogClient: catalogClient,
+		orderClient:   orderClient,
+	})
+	reflection.Register(serv)
+
+	return serv.Serve(lis)
+}
+
+func (s *grpcServer) GetCart(
+	ctx context.Context,
+	r *pb.GetCartRequest,
+) (*pb.GetCartResponse, error) {
+	cart, err := s.cartService.GetCart(ctx, r.AccountId)
+	if err != nil {
+		log.Println("Error getting cart: ", err)
+		return nil, err
+	}
+
+	if err := s.priceCart(ctx, cart); err != nil {
+		log.Println("Error pricing cart: ", err)
+		return nil, err
+	}
+
+	return &pb.GetCartResponse{Cart: cartToProto(cart)}, nil
+}
+
+func (s *grpcServer) AddCartItem(
+	ctx context.Context,
+	r *pb.AddCartItemRequest,
+) (*pb.AddCartItemResponse, error) {
+	// Check if product exists and can still be ordered
+	p, err := s.catalogClient.GetProduct(ctx, r.ProductId)
+	if errors.Is(err, catalog.ErrNotFound) || (err == nil && p.ArchivedAt != nil) {
+		return nil, grpcerr.New(codes.NotFound, reasonProductNotFound, fmt.Sprintf("product %s not found", r.ProductId))
+	}
+	if err != nil {
+		log.Println("Error getting product: ", err)
+		return nil, err
+	}
+
+	v := &validation.Error{}
+	if r.VariantId != "" {
+		_, ok := p.Variant(r.VariantId)
+		v.Check(ok, "variantId", "variant %s is not of product %s", r.VariantId, r.ProductId)
+	} else {
+		v.Check(len(p.Variants) == 0, "variantId", "is required for product %s, which has variants", r.ProductId)
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	// Lines already in the cart set its currency
+	price := p.Price
+	if variant, ok := p.Variant(r.VariantId); ok {
+		price = variant.Price
+	}
+	cart, err := s.cartService.GetCart(ctx, r.AccountId)
+	if err != nil {
+		log.Println("Error getting cart: ", err)
+		return nil, err
+	}
+	if err := s.priceCart(ctx, cart); err != nil {
+		log.Println("Error pricing cart: ", err)
+		return nil, err
+	}
+	for _, item := range cart.Items {
+		if !item.Unavailable && item.Price.Currency != price.Currency {
+			return nil, grpcerr.New(codes.FailedPrecondition, reasonMixedCurrencies, ErrMixedCurrencies.Error())
+		}
+	}
+
+	cart, err = s.cartService.AddItem(ctx, r.AccountId, r.ProductId, r.VariantId, r.Quantity)
+	if err != nil {
+		log.Println("Error adding cart item: ", err)
+		return nil, err
+	}
+
+	if err := s.priceCart(ctx, cart); err != nil {
+		log.Println("Error pricing cart: ", err)
+		return nil, err
+	}
+
+	return &pb.AddCartItemResponse{Cart: cartToProto(cart)}, nil
+}
+
+func (s *grpcServer) UpdateCartItem(
+	ctx context.Context,
+	r *pb.UpdateCartItemRequest,
+) (*pb.UpdateCartItemResponse, error) {
+	cart, err := s.cartService.UpdateItem(ctx, r.AccountId, r.ProductId, r.VariantId, r.Quantity)
+	if errors.Is(err, ErrNotFound) {
+		msg := fmt.Sprintf("product %s is not in the cart", r.ProductId)
+		if r.VariantId != "" {
+			msg = fmt.Sprintf("variant %s of product %s is not in the cart", r.VariantId, r.ProductId)
+		}
+		return nil, grpcerr.New(codes.NotFound, reasonCartItemNotFound, msg)
+	}
+	if err != nil {
+		log.Println("Error updating cart item: ", err)
+		return nil, err
+	}
+
+	if err := s.priceCart(ctx, cart); err != nil {
+		log.Println("Error pricing cart: ", err)
+		return nil, err
+	}
+
+	return &pb.UpdateCartItemResponse{Cart: cartToProto(cart)}, nil
+}
+
+func (s *grpcServer) RemoveCartItem(
+	ctx context.Context,
+	r *pb.RemoveCartItemRequest,
+) (*pb.RemoveCartItemResponse, error) {
+	cart, err := s.cartService.RemoveItem(ctx, r.AccountId, r.ProductId, r.VariantId)
+	if err != nil {
+		log.Println("Error removing cart item: ", err)
+		return nil, err
+	}
+
+	if err := s.priceCart(ctx, cart); err != nil {
+		log.Println("Error pricing cart: ", err)
+		return nil, err
+	}
+
+	return &pb.RemoveCartItemResponse{Cart: cartToProto(cart)}, nil
+}
+
+func (s *grpcServer) ClearCart(
+	ctx context.Context,
+	r *pb.ClearCartRequest,
+) (*pb.ClearCartResponse, error) {
+	cart, err := s.cartService.ClearCart(ctx, r.AccountId)
+	if err != nil {
+		log.Println("Error clearing cart: ", err)
+		return nil, err
+	}
+
+	if err := s.priceCart(ctx, cart); err != nil {
+		log.Println("Error pricing cart: ", err)
+		return nil, err
+	}
+
+	return &pb.ClearCartResponse{Cart: cartToProto(cart)}, nil
+}
+
+func (s *grpcServer) Checkout(
+	ctx context.Context,
+	r *pb.CheckoutRequest,
+) (*pb.CheckoutResponse, error) {
+	// A retry of a checkout that placed its order finds the cart cleared
+	orderID, err := s.cartService.GetCheckout(ctx, r.AccountId, r.IdempotencyKey)
+	if err != nil {
+		log.Println("Error getting checkout: ", err)
+		return nil, err
+	}
+	if orderID != "" {
+		return &pb.CheckoutResponse{OrderId: orderID}, nil
+	}
+
+	cart, err := s.cartService.GetCart(ctx, r.AccountId)
+	if err != nil {
+		log.Println("Error getting cart: ", err)
+		return nil, err
+	}
+
+	if len(cart.Items) == 0 {
+		return nil, grpcerr.New(codes.FailedPrecondition, reasonEmptyCart, ErrEmptyCart.Error())
+	}
+
+	if err := s.priceCart(ctx, cart); err != nil {
+		log.Println("Error pricing cart: ", err)
+		return nil, err
+	}
+
+	// The order service prices the lines itself at placement time
+	products := []order.OrderedProduct{}
+	for _, item := range cart.Items {
+		if item.Unavailable {
+			return nil, grpcerr.NewWithMetadata(
+				codes.FailedPrecondition,
+				reasonCartItemUnavailable,
+				ErrItemUnavailable.Error(),
+				map[string]string{"product_id": item.ProductID, "variant_id": item.VariantID},
+			)
+		}
+		products = append(products, order.OrderedProduct{
+			ID:        item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	o, err := s.orderClient.PostOrder(ctx, order.OrderRequest{
+		AccountID:         r.AccountId,
+		Products:          products,
+		IdempotencyKey:    r.IdempotencyKey,
+		CouponCodes:       r.CouponCodes,
+		ShippingAddress:   address.FromProto(r.ShippingAddress),
+		ShippingAddressID: r.ShippingAddressId,
+		BillingAddress:    address.FromProto(r.BillingAddress),
+		BillingAddressID:  r.BillingAddressId,
+		ShippingMethod:    r.ShippingMethod,
+	})
+	if err != nil {
+		log.Println("Error posting order: ", err)
+		return nil, err
+	}
+
+	// If this fails the cart is kept, and a retry with the same key gets
+	// the same order back from the order service
+	if err := s.cartService.CompleteCheckout(ctx, r.AccountId, r.IdempotencyKey, o.ID); err != nil {
+		log.Println("Error completing checkout: ", err)
+	}
+
+	return &pb.CheckoutResponse{
+		OrderId: o.ID,
+	}, nil
+}
+
+// priceCart fills in the current name, description and price of every
+// line, at the price of its variant for lines with one. Lines whose
+// product or variant no longer exists or whose product was archived are
+// marked unavailable and left out of the total, as are lines without a
+// variant for products that have since been given variants and lines
+// priced in another currency than the available lines before them.
+func (s *grpcServer) priceCart(ctx context.Context, cart *Cart) error {
+	cart.TotalPrice = money.Zero(money.DefaultCurrency)
+	if len(cart.Items) == 0 {
+		return nil
+	}
+
+	// Several variants of one product can be in the cart
+	productIDs := []string{}
+	seen := map[string]bool{}
+	for _, item := range cart.Items {
+		if !seen[item.ProductID] {
+			productIDs = append(productIDs, item.ProductID)
+			seen[item.ProductID] = true
+		}
+	}
+	products, err := s.catalogClient.GetProductsByIDs(ctx, productIDs, nil)
+	if err != nil {
+		return err
+	}
+
+	productMap := map[string]catalog.Product{}
+	for _, p := range products {
+		productMap[p.ID] = p
+	}
+
+	currency := ""
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		p, ok := productMap[item.ProductID]
+		if !ok {
+			item.Unavailable = true
+			continue
+		}
+		item.Name = p.Name
+		item.Description = p.Description
+		item.Price = p.Price
+		item.Unavailable = p.ArchivedAt != nil
+		if item.VariantID != "" {
+			variant, ok := p.Variant(item.VariantID)
+			if ok {
+				item.SKU = variant.SKU
+				item.VariantName = variant.Title()
+				item.Price = variant.Price
+			}
+			item.Unavailable = item.Unavailable || !ok
+		} else {
+			item.Unavailable = item.Unavailable || len(p.Variants) > 0
+		}
+		if item.Unavailable {
+			continue
+		}
+		if currency == "" {
+			currency = item.Price.Currency
+			cart.TotalPrice = money.Zero(currency)
+		}
+		if item.Price.Currency != currency {
+			item.Unavailable = true
+			continue
+		}
+		cart.TotalPrice, err = cart.TotalPrice.Add(item.Price.Mul(int64(item.Quantity)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func cartToProto(c *Cart) *pb.Cart {
+	cp := &pb.Cart{
+		AccountId:  c.AccountID,
+		Items:      []*pb.CartItem{},
+		TotalPrice: c.TotalPrice.Proto(),
+	}
+
+	for _, item := range c.Items {
+		cp.Items = append(cp.Items, &pb.CartItem{
+			ProductId:   item.ProductID,
+			VariantId:   item.VariantID,
+			Sku:         item.SKU,
+			VariantName: item.VariantName,
+			Name:        item.Name,
+			Description: item.Description,
+			Price:       item.Price.Proto(),
+			Quantity:    item.Quantity,
+			Unavailable: item.Unavailable,
+		})
+	}
+
+	return cp
+}
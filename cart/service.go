@@ -0,0 +1,158 @@
+package cart
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+)
+
+type (
+	CartService interface {
+		GetCart(ctx context.Context, accountID string) (*Cart, error)
+		AddItem(ctx context.Context, accountID string, productID string, variantID string, quantity uint32) (*Cart, error)
+		UpdateItem(ctx context.Context, accountID string, productID string, variantID string, quantity uint32) (*Cart, error)
+		RemoveItem(ctx context.Context, accountID string, productID string, variantID string) (*Cart, error)
+		ClearCart(ctx context.Context, accountID string) (*Cart, error)
+		GetCheckout(ctx context.Context, accountID string, idempotencyKey string) (orderID string, err error)
+		CompleteCheckout(ctx context.Context, accountID string, idempotencyKey string, orderID string) error
+	}
+
+	Cart struct {
+		AccountID  string      `json:"account_id"`
+		Items      []CartItem  `json:"items"`
+		TotalPrice money.Money `json:"total_price"`
+	}
+
+	CartItem struct {
+		ProductID   string      `json:"product_id"`
+		VariantID   string      `json:"variant_id,omitempty"`
+		SKU         string      `json:"sku,omitempty"`
+		VariantName string      `json:"variant_name,omitempty"`
+		Name        string      `json:"name"`
+		Description string      `json:"description"`
+		Price       money.Money `json:"price"`
+		Quantity    uint32      `json:"quantity"`
+
+		// Unavailable is set for lines whose product or variant no longer
+		// exists or was archived, or that are priced in another currency
+		// than the rest of the cart. They are kept in the cart but left out
+		// of its total and cannot be checked out.
+		Unavailable bool `json:"unavailable,omitempty"`
+	}
+
+	cartService struct {
+		repository CartRepository
+	}
+)
+
+func NewCartService(r CartRepository) CartService {
+	return &cartService{r}
+}
+
+func (s *cartService) GetCart(
+	ctx context.Context,
+	accountID string,
+) (*Cart, error) {
+	items, err := s.repository.GetCartItems(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cart{
+		AccountID: accountID,
+		Items:     items,
+	}, nil
+}
+
+func (s *cartService) AddItem(
+	ctx context.Context,
+	accountID string,
+	productID string,
+	variantID string,
+	quantity uint32,
+) (*Cart, error) {
+	if quantity > 0 {
+		err := s.repository.AddCartItem(ctx, accountID, productID, variantID, quantity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetCart(ctx, accountID)
+}
+
+func (s *cartService) UpdateItem(
+	ctx context.Context,
+	accountID string,
+	productID string,
+	variantID string,
+	quantity uint32,
+) (*Cart, error) {
+	if quantity == 0 {
+		return s.RemoveItem(ctx, accountID, productID, variantID)
+	}
+
+	err := s.repository.SetCartItemQuantity(ctx, accountID, productID, variantID, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, accountID)
+}
+
+func (s *cartService) RemoveItem(
+	ctx context.Context,
+	accountID string,
+	productID string,
+	variantID string,
+) (*Cart, error) {
+	err := s.repository.RemoveCartItem(ctx, accountID, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, accountID)
+}
+
+func (s *cartService) ClearCart(
+	ctx context.Context,
+	accountID string,
+) (*Cart, error) {
+	err := s.repository.ClearCart(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cart{
+		AccountID: accountID,
+		Items:     []CartItem{},
+	}, nil
+}
+
+// GetCheckout returns the order an earlier checkout with the idempotency
+// key placed, or "" if there is none.
+func (s *cartService) GetCheckout(
+	ctx context.Context,
+	accountID string,
+	idempotencyKey string,
+) (string, error) {
+	if idempotencyKey == "" {
+		return "", nil
+	}
+
+	orderID, err := s.repository.GetCheckout(ctx, accountID, idempotencyKey)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	}
+	return orderID, err
+}
+
+func (s *cartService) CompleteCheckout(
+	ctx context.Context,
+	accountID string,
+	idempotencyKey string,
+	orderID string,
+) error {
+	return s.repository.CompleteCheckout(ctx, accountID, idempotencyKey, orderID)
+}
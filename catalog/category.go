@@ -0,0 +1,121 @@
+package catalog
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
+)
+
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrCategoryHasChildren = errors.New("category has child categories")
+)
+
+const (
+	MaxCategoryNameLength = 64
+	MaxSlugLength         = 64
+
+	// MaxCategories bounds the category tree, which is always read whole.
+	MaxCategories = 1000
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type (
+	// Category is a node of the category tree. Root categories have no
+	// ParentID, siblings are ordered by Position and then by name.
+	Category struct {
+		ID       string `json:"id"`
+		Slug     string `json:"slug"`
+		Name     string `json:"name"`
+		ParentID string `json:"parent_id,omitempty"`
+		Position int32  `json:"position"`
+	}
+
+	// categoryTree indexes the whole category list, which is small enough
+	// to be loaded for every change.
+	categoryTree struct {
+		byID     map[string]Category
+		children map[string][]string
+	}
+)
+
+func (c Category) Validate() error {
+	v := &validation.Error{}
+	v.Check(validation.Length(c.Name, 1, MaxCategoryNameLength), "name", "is required and must be at most %d characters", MaxCategoryNameLength)
+	v.Check(len(c.Slug) <= MaxSlugLength && slugPattern.MatchString(c.Slug), "slug", "must be at most %d lower case letters, digits and single dashes", MaxSlugLength)
+	return v.Err()
+}
+
+// sortCategories orders categories the way siblings are listed.
+func sortCategories(categories []Category) {
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].Position != categories[j].Position {
+			return categories[i].Position < categories[j].Position
+		}
+		return categories[i].Name < categories[j].Name
+	})
+}
+
+func newCategoryTree(categories []Category) *categoryTree {
+	t := &categoryTree{
+		byID:     map[string]Category{},
+		children: map[string][]string{},
+	}
+	for _, c := range categories {
+		t.byID[c.ID] = c
+		t.children[c.ParentID] = append(t.children[c.ParentID], c.ID)
+	}
+	return t
+}
+
+// check validates c and its place in the tree: the slug must be unused by
+// other categories and the parent must exist outside of c's subtree.
+func (t *categoryTree) check(c Category) error {
+	v := &validation.Error{}
+	v.Merge("", c.Validate())
+	for _, other := range t.byID {
+		v.Check(other.ID == c.ID || other.Slug != c.Slug, "slug", "is taken by category %s", other.ID)
+	}
+	if c.ParentID != "" {
+		_, ok := t.byID[c.ParentID]
+		v.Check(ok, "parentId", "category %s does not exist", c.ParentID)
+		v.Check(!ok || !t.isAncestor(c.ID, c.ParentID), "parentId", "cannot be the category itself or one of its descendants")
+	}
+	return v.Err()
+}
+
+// find returns the category with the given ID or slug.
+func (t *categoryTree) find(idOrSlug string) (Category, bool) {
+	if c, ok := t.byID[idOrSlug]; ok {
+		return c, true
+	}
+	for _, c := range t.byID {
+		if c.Slug == idOrSlug {
+			return c, true
+		}
+	}
+	return Category{}, false
+}
+
+// subtree returns the ID of the category and of all its descendants.
+func (t *categoryTree) subtree(id string) []string {
+	ids := []string{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, t.children[ids[i]]...)
+	}
+	return ids
+}
+
+// isAncestor reports whether ancestor is id itself or one of its parents.
+func (t *categoryTree) isAncestor(ancestor string, id string) bool {
+	for seen := 0; id != "" && seen <= len(t.byID); seen++ {
+		if id == ancestor {
+			return true
+		}
+		id = t.byID[id].ParentID
+	}
+	return false
+}
@@ -2,11 +2,17 @@ package catalog
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
 
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
 	pb "github.com/stiffinWanjohi/go-ecommerce/catalog/pb"
+	"github.com/stiffinWanjohi/go-ecommerce/grpcerr"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type Client struct {
@@ -14,10 +20,14 @@ type Client struct {
 	service pb.CatalogServiceClient
 }
 
-func NewClient(url string) (*Client, error) {
+func NewClient(url string, id *auth.ServiceIdentity) (*Client, error) {
 	conn, err := grpc.NewClient(
 		url,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(
+			auth.UnaryClientInterceptor(id),
+			grpcerr.UnaryClientInterceptor(reasons),
+		),
 	)
 	if err != nil {
 		return nil, err
@@ -36,26 +46,33 @@ func (c *Client) PostProduct(
 	ctx context.Context,
 	name string,
 	description string,
-	price float64,
+	price money.Money,
+	stock uint32,
+	taxCategory string,
+	categoryIDs []string,
+	options []Option,
+	variants []Variant,
+	idempotencyKey string,
 ) (*Product, error) {
 	r, err := c.service.PostProduct(
 		ctx,
 		&pb.PostProductRequest{
-			Name:        name,
-			Description: description,
-			Price:       price,
+			Name:           name,
+			Description:    description,
+			Price:          price.Proto(),
+			Stock:          stock,
+			TaxCategory:    taxCategory,
+			CategoryIds:    categoryIDs,
+			Options:        optionsToProto(options),
+			Variants:       variantsToProto(variants),
+			IdempotencyKey: idempotencyKey,
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Product{
-		ID:          r.Product.Id,
-		Name:        r.Product.Name,
-		Description: r.Product.Description,
-		Price:       r.Product.Price,
-	}, nil
+	return productFromProto(r.Product), nil
 }
 
 func (c *Client) GetProduct(
@@ -72,12 +89,7 @@ func (c *Client) GetProduct(
 		return nil, err
 	}
 
-	return &Product{
-		ID:          r.Product.Id,
-		Name:        r.Product.Name,
-		Description: r.Product.Description,
-		Price:       r.Product.Price,
-	}, nil
+	return productFromProto(r.Product), nil
 }
 
 func (c *Client) GetProducts(
@@ -85,14 +97,88 @@ func (c *Client) GetProducts(
 	skip, take uint64,
 	ids []string,
 	query string,
+	category string,
+	sort Sort,
+) ([]Product, uint64, error) {
+	r, err := c.service.GetProducts(
+		ctx,
+		&pb.GetProductsRequest{
+			Skip:     skip,
+			Take:     take,
+			Ids:      ids,
+			Query:    query,
+			Category: category,
+			Sort:     sort.Proto(),
+		},
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	products := []Product{}
+	for _, a := range r.Products {
+		products = append(products, *productFromProto(a))
+	}
+
+	return products, r.Total, nil
+}
+
+// Search returns a page of the products matching q, the number of all
+// matching products and their facets.
+func (c *Client) Search(
+	ctx context.Context,
+	q SearchQuery,
+) (*SearchResult, error) {
+	request := &pb.SearchProductsRequest{
+		Query:      q.Query,
+		Categories: q.Categories,
+		InStock:    q.InStock,
+		Sort:       q.Sort.Proto(),
+		Skip:       q.Skip,
+		Take:       q.Take,
+	}
+	if q.MinPrice != nil {
+		request.MinPrice = q.MinPrice.Proto()
+	}
+	if q.MaxPrice != nil {
+		request.MaxPrice = q.MaxPrice.Proto()
+	}
+	for _, a := range q.Attributes {
+		request.Attributes = append(request.Attributes, &pb.SearchProductsRequest_AttributeFilter{
+			Name:   a.Name,
+			Values: a.Values,
+		})
+	}
+
+	r, err := c.service.SearchProducts(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{
+		Products: []Product{},
+		Total:    r.Total,
+		Facets:   facetsFromProto(r.Facets),
+	}
+	for _, p := range r.Products {
+		result.Products = append(result.Products, *productFromProto(p))
+	}
+
+	return result, nil
+}
+
+// GetProductsByIDs returns the products with the given IDs and those with
+// one of the given variants.
+func (c *Client) GetProductsByIDs(
+	ctx context.Context,
+	ids []string,
+	variantIDs []string,
 ) ([]Product, error) {
 	r, err := c.service.GetProducts(
 		ctx,
 		&pb.GetProductsRequest{
-			Skip:  skip,
-			Take:  take,
-			Ids:   ids,
-			Query: query,
+			Ids:        ids,
+			VariantIds: variantIDs,
 		},
 	)
 	if err != nil {
@@ -101,15 +187,333 @@ func (c *Client) GetProducts(
 
 	products := []Product{}
 	for _, a := range r.Products {
-		products = append(products,
-			Product{
-				ID:          a.Id,
-				Name:        a.Name,
-				Description: a.Description,
-				Price:       a.Price,
-			},
-		)
+		products = append(products, *productFromProto(a))
 	}
 
 	return products, nil
 }
+
+// UpdateProduct changes the given fields of the product to those of p,
+// or all of them when fields is empty.
+func (c *Client) UpdateProduct(
+	ctx context.Context,
+	p Product,
+	fields []string,
+) (*Product, error) {
+	r, err := c.service.UpdateProduct(
+		ctx,
+		&pb.UpdateProductRequest{
+			Id:          p.ID,
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       p.Price.Proto(),
+			Stock:       p.Stock,
+			TaxCategory: p.TaxCategory,
+			CategoryIds: p.CategoryIDs,
+			Options:     optionsToProto(p.Options),
+			Variants:    variantsToProto(p.Variants),
+			UpdateMask:  &fieldmaskpb.FieldMask{Paths: fields},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return productFromProto(r.Product), nil
+}
+
+func (c *Client) DeleteProduct(
+	ctx context.Context,
+	id string,
+) (*Product, error) {
+	r, err := c.service.DeleteProduct(
+		ctx,
+		&pb.DeleteProductRequest{
+			Id: id,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return productFromProto(r.Product), nil
+}
+
+func (c *Client) PostCategory(
+	ctx context.Context,
+	category Category,
+) (*Category, error) {
+	r, err := c.service.PostCategory(
+		ctx,
+		&pb.PostCategoryRequest{
+			Slug:     category.Slug,
+			Name:     category.Name,
+			ParentId: category.ParentID,
+			Position: category.Position,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return categoryFromProto(r.Category), nil
+}
+
+// GetCategories returns the whole category tree as a list, siblings in
+// their order.
+func (c *Client) GetCategories(ctx context.Context) ([]Category, error) {
+	r, err := c.service.GetCategories(ctx, &pb.GetCategoriesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	categories := []Category{}
+	for _, category := range r.Categories {
+		categories = append(categories, *categoryFromProto(category))
+	}
+
+	return categories, nil
+}
+
+// UpdateCategory changes the given fields of the category to those of
+// category, or all of them when fields is empty.
+func (c *Client) UpdateCategory(
+	ctx context.Context,
+	category Category,
+	fields []string,
+) (*Category, error) {
+	r, err := c.service.UpdateCategory(
+		ctx,
+		&pb.UpdateCategoryRequest{
+			Id:         category.ID,
+			Slug:       category.Slug,
+			Name:       category.Name,
+			ParentId:   category.ParentID,
+			Position:   category.Position,
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: fields},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return categoryFromProto(r.Category), nil
+}
+
+func (c *Client) DeleteCategory(
+	ctx context.Context,
+	id string,
+) error {
+	_, err := c.service.DeleteCategory(
+		ctx,
+		&pb.DeleteCategoryRequest{
+			Id: id,
+		},
+	)
+	return err
+}
+
+func (c *Client) ReserveStock(
+	ctx context.Context,
+	items []StockItem,
+) (string, error) {
+	protoItems := []*pb.StockItem{}
+	for _, item := range items {
+		protoItems = append(protoItems, &pb.StockItem{
+			ProductId: item.ProductID,
+			VariantId: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	r, err := c.service.ReserveStock(
+		ctx,
+		&pb.ReserveStockRequest{
+			Items: protoItems,
+		},
+	)
+	if err != nil {
+		return "", stockErrorFromStatus(err)
+	}
+
+	return r.ReservationId, nil
+}
+
+func (c *Client) ReleaseStock(
+	ctx context.Context,
+	reservationID string,
+) error {
+	_, err := c.service.ReleaseStock(
+		ctx,
+		&pb.ReleaseStockRequest{
+			ReservationId: reservationID,
+		},
+	)
+	return err
+}
+
+func (c *Client) CommitStock(
+	ctx context.Context,
+	reservationID string,
+) error {
+	_, err := c.service.CommitStock(
+		ctx,
+		&pb.CommitStockRequest{
+			ReservationId: reservationID,
+		},
+	)
+	return err
+}
+
+func (c *Client) ConfirmReservation(
+	ctx context.Context,
+	reservationID string,
+) error {
+	_, err := c.service.ConfirmReservation(
+		ctx,
+		&pb.ConfirmReservationRequest{
+			ReservationId: reservationID,
+		},
+	)
+	return err
+}
+
+// GetExpiredReservations returns the IDs of up to take reservations that
+// still hold stock past their expiry.
+func (c *Client) GetExpiredReservations(
+	ctx context.Context,
+	take uint64,
+) ([]string, error) {
+	r, err := c.service.GetExpiredReservations(
+		ctx,
+		&pb.GetExpiredReservationsRequest{
+			Take: take,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ReservationIds, nil
+}
+
+// stockErrorFromStatus turns an insufficient stock status back into an
+// *InsufficientStockError so callers can tell it apart from outages.
+func stockErrorFromStatus(err error) error {
+	var statusErr *grpcerr.Error
+	if errors.As(err, &statusErr) && statusErr.Reason() == reasonInsufficientStock {
+		return &InsufficientStockError{
+			ProductID: statusErr.Metadata()["product_id"],
+			VariantID: statusErr.Metadata()["variant_id"],
+		}
+	}
+	return err
+}
+
+func productFromProto(p *pb.Product) *Product {
+	product := &Product{
+		ID:          p.Id,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       money.FromProto(p.Price),
+		Stock:       p.Stock,
+		Reserved:    p.ReservedStock,
+		TaxCategory: p.TaxCategory,
+		CategoryIDs: p.CategoryIds,
+		Options:     optionsFromProto(p.Options),
+		Variants:    variantsFromProto(p.Variants),
+	}
+	if len(p.CreatedAt) > 0 {
+		product.CreatedAt.UnmarshalBinary(p.CreatedAt)
+	}
+	if len(p.ArchivedAt) > 0 {
+		product.ArchivedAt = &time.Time{}
+		product.ArchivedAt.UnmarshalBinary(p.ArchivedAt)
+	}
+	return product
+}
+
+func optionsFromProto(pbOptions []*pb.Option) []Option {
+	if len(pbOptions) == 0 {
+		return nil
+	}
+
+	options := make([]Option, len(pbOptions))
+	for index, o := range pbOptions {
+		options[index] = Option{
+			Name:   o.Name,
+			Values: o.Values,
+		}
+	}
+	return options
+}
+
+func variantsFromProto(pbVariants []*pb.Variant) []Variant {
+	if len(pbVariants) == 0 {
+		return nil
+	}
+
+	variants := make([]Variant, len(pbVariants))
+	for index, v := range pbVariants {
+		values := make([]OptionValue, len(v.Options))
+		for i, o := range v.Options {
+			values[i] = OptionValue{
+				Name:  o.Name,
+				Value: o.Value,
+			}
+		}
+		variants[index] = Variant{
+			ID:       v.Id,
+			SKU:      v.Sku,
+			Options:  values,
+			Price:    money.FromProto(v.Price),
+			Stock:    v.Stock,
+			Reserved: v.ReservedStock,
+		}
+	}
+	return variants
+}
+
+func facetsFromProto(pf *pb.Facets) Facets {
+	f := Facets{
+		Categories: facetValuesFromProto(pf.GetCategories()),
+		Attributes: []AttributeFacet{},
+		Prices:     []PriceRange{},
+		InStock:    pf.GetInStock(),
+		OutOfStock: pf.GetOutOfStock(),
+	}
+	for _, a := range pf.GetAttributes() {
+		f.Attributes = append(f.Attributes, AttributeFacet{
+			Name:   a.Name,
+			Values: facetValuesFromProto(a.Values),
+		})
+	}
+	for _, p := range pf.GetPrices() {
+		f.Prices = append(f.Prices, PriceRange{
+			Min: money.FromProto(p.Min),
+			Max: money.FromProto(p.Max),
+		})
+	}
+	return f
+}
+
+func facetValuesFromProto(pbValues []*pb.FacetValue) []FacetValue {
+	values := make([]FacetValue, len(pbValues))
+	for index, v := range pbValues {
+		values[index] = FacetValue{
+			Value: v.Value,
+			Count: v.Count,
+		}
+	}
+	return values
+}
+
+func categoryFromProto(c *pb.Category) *Category {
+	return &Category{
+		ID:       c.Id,
+		Slug:     c.Slug,
+		Name:     c.Name,
+		ParentID: c.ParentId,
+		Position: c.Position,
+	}
+}
@@ -5,12 +5,18 @@ import (
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
 	"github.com/stiffinWanjohi/go-ecommerce/catalog"
 	"github.com/tinrab/retry"
 )
 
+// serviceTokenTTL is how long the tokens this service presents to others
+// are valid. They are signed for every call.
+const serviceTokenTTL = time.Minute
+
 type Config struct {
-	DatabaseURL string `envconfig:"DATABASE_URL"`
+	DatabaseURL   string `envconfig:"DATABASE_URL"`
+	SessionSecret string `envconfig:"SESSION_SECRET" required:"true"`
 }
 
 func main() {
@@ -34,5 +40,5 @@ func main() {
 
 	log.Println("Listening on port 8001...")
 	s := catalog.NewCatalogService(r)
-	log.Fatal(catalog.ListenGRPC(s, 8001))
+	log.Fatal(catalog.ListenGRPC(s, auth.NewSigner(cfg.SessionSecret, serviceTokenTTL), 8001))
 }
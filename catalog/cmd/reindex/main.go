@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/stiffinWanjohi/go-ecommerce/catalog"
+)
+
+type Config struct {
+	DatabaseURL string `envconfig:"DATABASE_URL"`
+	// DeleteOldIndex drops the index the alias pointed at once it has
+	// moved, instead of keeping it to switch back to.
+	DeleteOldIndex bool `envconfig:"DELETE_OLD_INDEX"`
+}
+
+func main() {
+	var cfg Config
+	err := envconfig.Process("", &cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Elasticsearch URL:", cfg.DatabaseURL)
+
+	index, err := catalog.Reindex(context.Background(), cfg.DatabaseURL, cfg.DeleteOldIndex)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Catalog alias now points at", index)
+}
@@ -0,0 +1,479 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// Products are read and written through the catalog alias, which points at
+// one versioned index, catalog_v1, catalog_v2 and so on. Reindexing builds
+// the next version and moves the alias to it in one step.
+const (
+	catalogAlias       = "catalog"
+	catalogIndexPrefix = "catalog_v"
+
+	// copyScript fills in the id field of documents written before it was
+	// kept, so that every product can be sorted by it.
+	copyScript = `if (ctx._source.id == null) { ctx._source.id = ctx._id; }`
+)
+
+// productIndex is the settings and mapping every catalog index is created
+// with. Fields not listed are kept in the source but not indexed.
+var productIndex = map[string]interface{}{
+	"settings": map[string]interface{}{
+		"analysis": map[string]interface{}{
+			"analyzer": map[string]interface{}{
+				// Folds accents so that "creme" finds "Crème"
+				"product_text": map[string]interface{}{
+					"type":      "custom",
+					"tokenizer": "standard",
+					"filter":    []string{"lowercase", "asciifolding"},
+				},
+			},
+			"normalizer": map[string]interface{}{
+				"sortable": map[string]interface{}{
+					"type":   "custom",
+					"filter": []string{"lowercase", "asciifolding"},
+				},
+			},
+		},
+	},
+	"mappings": map[string]interface{}{
+		"dynamic": false,
+		"properties": map[string]interface{}{
+			"id": keywordField,
+			"name": map[string]interface{}{
+				"type":     "text",
+				"analyzer": "product_text",
+				"fields": map[string]interface{}{
+					"sort": map[string]interface{}{
+						"type":       "keyword",
+						"normalizer": "sortable",
+					},
+				},
+			},
+			"description": map[string]interface{}{
+				"type":     "text",
+				"analyzer": "product_text",
+			},
+			"price_amount": longField,
+			"currency":     keywordField,
+			"stock":        longField,
+			"reserved":     longField,
+			"tax_category": keywordField,
+			"category_ids": keywordField,
+			"options": map[string]interface{}{
+				"type":    "object",
+				"enabled": false,
+			},
+			// Nested so that a query on a variant or an attribute matches
+			// the fields of one rather than those of any
+			"variants": map[string]interface{}{
+				"type": "nested",
+				"properties": map[string]interface{}{
+					"id":           keywordField,
+					"sku":          keywordField,
+					"options":      optionValueField,
+					"price_amount": longField,
+					"currency":     keywordField,
+					"stock":        longField,
+					"reserved":     longField,
+				},
+			},
+			"attributes": map[string]interface{}{
+				"type":       "nested",
+				"properties": optionValueField["properties"],
+			},
+			"created_at":  dateField,
+			"archived_at": dateField,
+			// The float price of products written before prices were kept
+			// in minor units
+			"price": map[string]interface{}{"type": "double"},
+		},
+	},
+}
+
+var (
+	keywordField = map[string]interface{}{"type": "keyword"}
+	longField    = map[string]interface{}{"type": "long"}
+	dateField    = map[string]interface{}{"type": "date"}
+
+	optionValueField = map[string]interface{}{
+		"properties": map[string]interface{}{
+			"name":  keywordField,
+			"value": keywordField,
+		},
+	}
+)
+
+func catalogIndex(version int) string {
+	return catalogIndexPrefix + strconv.Itoa(version)
+}
+
+// catalogIndexVersion is the version of a catalog index name, or 0 for
+// the unversioned index used before the alias.
+func catalogIndexVersion(index string) int {
+	version, err := strconv.Atoi(strings.TrimPrefix(index, catalogIndexPrefix))
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// bootstrapIndex makes sure the catalog alias exists. A new cluster gets
+// the first index version. A catalog index made before the alias, with a
+// dynamic mapping, is copied into the first version and replaced by the
+// alias.
+func bootstrapIndex(ctx context.Context, client *elasticsearch.Client) error {
+	current, err := aliasTarget(ctx, client)
+	if err != nil {
+		return err
+	}
+	if current != "" {
+		return nil
+	}
+
+	legacy, err := indexExists(ctx, client, catalogAlias)
+	if err != nil {
+		return err
+	}
+	if legacy {
+		log.Println("Migrating the catalog index to", catalogIndex(1))
+		return moveAlias(ctx, client, catalogAlias, catalogIndex(1))
+	}
+
+	if err := createIndex(ctx, client, catalogIndex(1)); err != nil {
+		return err
+	}
+	return updateAliases(ctx, client, map[string]interface{}{
+		"add": map[string]interface{}{"index": catalogIndex(1), "alias": catalogAlias},
+	})
+}
+
+// Reindex copies the products into a new index version, created with the
+// current mapping, and points the catalog alias at it. Searches keep being
+// served by the old index throughout. Writes are only refused while the
+// changes made during the copy are carried over. The old index is kept,
+// closed to writes, unless deleteOld is set. It returns the name of the
+// new index.
+func Reindex(
+	ctx context.Context,
+	url string,
+	deleteOld bool,
+) (string, error) {
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: []string{url},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := bootstrapIndex(ctx, client); err != nil {
+		return "", err
+	}
+
+	current, err := aliasTarget(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	next := catalogIndex(catalogIndexVersion(current) + 1)
+
+	log.Printf("Reindexing %s into %s", current, next)
+	if err := moveAlias(ctx, client, current, next); err != nil {
+		return "", err
+	}
+
+	if deleteOld {
+		res, err := client.Indices.Delete(
+			[]string{current},
+			client.Indices.Delete.WithContext(ctx),
+		)
+		if err != nil {
+			return "", fmt.Errorf("failed to delete index: %w", err)
+		}
+		defer res.Body.Close()
+		if res.IsError() {
+			return "", fmt.Errorf("failed to delete index, status: %s", res.Status())
+		}
+	}
+
+	return next, nil
+}
+
+// moveAlias creates the index to with the current mapping, copies the
+// products of from into it and then points the catalog alias at it. from
+// is either the index the alias points at or the unversioned catalog
+// index, which is removed in the same step.
+func moveAlias(
+	ctx context.Context,
+	client *elasticsearch.Client,
+	from string,
+	to string,
+) error {
+	if err := createIndex(ctx, client, to); err != nil {
+		return err
+	}
+
+	// Copy while writes go on, then block them and carry over what changed
+	// meanwhile. External versions skip the documents copied unchanged.
+	if err := copyIndex(ctx, client, from, to); err != nil {
+		return err
+	}
+	if err := blockWrites(ctx, client, from, true); err != nil {
+		return err
+	}
+	if err := copyIndex(ctx, client, from, to); err != nil {
+		if unblockErr := blockWrites(ctx, client, from, false); unblockErr != nil {
+			log.Println("Error unblocking writes: ", unblockErr)
+		}
+		return err
+	}
+
+	remove := map[string]interface{}{
+		"remove": map[string]interface{}{"index": from, "alias": catalogAlias},
+	}
+	if from == catalogAlias {
+		remove = map[string]interface{}{
+			"remove_index": map[string]interface{}{"index": from},
+		}
+	}
+	err := updateAliases(ctx, client,
+		remove,
+		map[string]interface{}{
+			"add": map[string]interface{}{"index": to, "alias": catalogAlias},
+		},
+	)
+	if err != nil {
+		if unblockErr := blockWrites(ctx, client, from, false); unblockErr != nil {
+			log.Println("Error unblocking writes: ", unblockErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// aliasTarget is the index the catalog alias points at, or "" if there is
+// no alias yet.
+func aliasTarget(
+	ctx context.Context,
+	client *elasticsearch.Client,
+) (string, error) {
+	res, err := client.Indices.GetAlias(
+		client.Indices.GetAlias.WithContext(ctx),
+		client.Indices.GetAlias.WithName(catalogAlias),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to get alias: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return "", nil
+	}
+	if res.IsError() {
+		return "", fmt.Errorf("failed to get alias, status: %s", res.Status())
+	}
+
+	var indices map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return "", fmt.Errorf("failed to decode alias: %w", err)
+	}
+	if len(indices) != 1 {
+		return "", fmt.Errorf("catalog alias points at %d indices", len(indices))
+	}
+	for index := range indices {
+		return index, nil
+	}
+	return "", nil
+}
+
+func indexExists(
+	ctx context.Context,
+	client *elasticsearch.Client,
+	index string,
+) (bool, error) {
+	res, err := client.Indices.Exists(
+		[]string{index},
+		client.Indices.Exists.WithContext(ctx),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to check index: %w", err)
+	}
+	res.Body.Close()
+
+	return res.StatusCode == 200, nil
+}
+
+// createIndex creates index with the product mapping. An index left by an
+// earlier attempt is reused.
+func createIndex(
+	ctx context.Context,
+	client *elasticsearch.Client,
+	index string,
+) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(productIndex); err != nil {
+		return fmt.Errorf("failed to encode mapping: %w", err)
+	}
+
+	res, err := client.Indices.Create(
+		index,
+		client.Indices.Create.WithContext(ctx),
+		client.Indices.Create.WithBody(&buf),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() && errorType(res) != "resource_already_exists_exception" {
+		return fmt.Errorf("failed to create index %s, status: %s", index, res.Status())
+	}
+
+	return nil
+}
+
+// copyIndex copies every document of from that is newer in from than in
+// to.
+func copyIndex(
+	ctx context.Context,
+	client *elasticsearch.Client,
+	from string,
+	to string,
+) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{
+		"conflicts": "proceed",
+		"source": map[string]interface{}{
+			"index": from,
+		},
+		"dest": map[string]interface{}{
+			"index":        to,
+			"version_type": "external",
+		},
+		"script": map[string]interface{}{
+			"source": copyScript,
+			"lang":   "painless",
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to encode reindex: %w", err)
+	}
+
+	res, err := client.Reindex(
+		&buf,
+		client.Reindex.WithContext(ctx),
+		client.Reindex.WithRefresh(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reindex: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to reindex, status: %s", res.Status())
+	}
+
+	var result struct {
+		Failures []interface{} `json:"failures"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode reindex response: %w", err)
+	}
+	if len(result.Failures) > 0 {
+		return fmt.Errorf("failed to reindex %d documents: %v", len(result.Failures), result.Failures[0])
+	}
+
+	return nil
+}
+
+func blockWrites(
+	ctx context.Context,
+	client *elasticsearch.Client,
+	index string,
+	block bool,
+) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{
+		"index.blocks.write": block,
+	}); err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	res, err := client.Indices.PutSettings(
+		&buf,
+		client.Indices.PutSettings.WithContext(ctx),
+		client.Indices.PutSettings.WithIndex(index),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to block writes: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to block writes, status: %s", res.Status())
+	}
+
+	return nil
+}
+
+// updateAliases applies actions together, so that searches always find
+// the alias pointing at exactly one index.
+func updateAliases(
+	ctx context.Context,
+	client *elasticsearch.Client,
+	actions ...interface{},
+) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{
+		"actions": actions,
+	}); err != nil {
+		return fmt.Errorf("failed to encode aliases: %w", err)
+	}
+
+	res, err := client.Indices.UpdateAliases(
+		&buf,
+		client.Indices.UpdateAliases.WithContext(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update aliases: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to update aliases, status: %s", res.Status())
+	}
+
+	return nil
+}
+
+// errorType is the type of the error in an Elasticsearch error response.
+func errorType(res *esapi.Response) string {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return ""
+	}
+	var e struct {
+		Error struct {
+			Type string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &e); err != nil {
+		return ""
+	}
+	return e.Error.Type
+}
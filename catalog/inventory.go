@@ -0,0 +1,71 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrReservationSettled = errors.New("reservation already settled")
+)
+
+// ReservationTTL is how long a reservation holds stock unless it is
+// confirmed. Expired reservations are released by the order service's
+// sweeper when no order was placed with them.
+const ReservationTTL = 15 * time.Minute
+
+type ReservationStatus string
+
+const (
+	ReservationReserved  ReservationStatus = "reserved"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationCommitted ReservationStatus = "committed"
+)
+
+type (
+	// StockItem is a quantity of a product, or of one of its variants
+	// when VariantID is set.
+	StockItem struct {
+		ProductID string `json:"product_id"`
+		VariantID string `json:"variant_id,omitempty"`
+		Quantity  uint32 `json:"quantity"`
+	}
+
+	// Reservation holds stock for a pending order until it is either
+	// committed (the goods leave the warehouse) or released. ExpiresAt is
+	// cleared once the order is placed.
+	Reservation struct {
+		ID        string            `json:"id"`
+		Items     []StockItem       `json:"items"`
+		Status    ReservationStatus `json:"status"`
+		CreatedAt time.Time         `json:"created_at"`
+		ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+
+		// Holds is set on reservations that record their quantities on
+		// the stock they hold, so that releasing them is exact and can be
+		// repeated. Older reservations release their quantities as is.
+		Holds bool `json:"holds,omitempty"`
+	}
+
+	InsufficientStockError struct {
+		ProductID string
+		VariantID string
+	}
+)
+
+func (e *InsufficientStockError) Error() string {
+	if e.VariantID != "" {
+		return fmt.Sprintf("insufficient stock for variant %s of product %s", e.VariantID, e.ProductID)
+	}
+	return fmt.Sprintf("insufficient stock for product %s", e.ProductID)
+}
+
+// holdID is the key the reservation's quantities are held under on the
+// stock, or "" for reservations that do not record them.
+func (r Reservation) holdID() string {
+	if !r.Holds {
+		return ""
+	}
+	return r.ID
+}
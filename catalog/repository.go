@@ -7,12 +7,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/stiffinWanjohi/go-ecommerce/idempotency"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+	"github.com/stiffinWanjohi/go-ecommerce/tax"
 )
 
 var (
-	ErrNotFound = errors.New("entity not found")
+	ErrNotFound              = errors.New("entity not found")
+	ErrIdempotencyKeyClaimed = errors.New("idempotency key already claimed")
 )
 
 type (
@@ -20,9 +26,27 @@ type (
 		Close()
 		PutProduct(ctx context.Context, p Product) error
 		GetProductByID(ctx context.Context, id string) (*Product, error)
-		ListProducts(ctx context.Context, skip uint64, take uint64) ([]Product, error)
-		ListProductsWithIDs(ctx context.Context, ids []string) ([]Product, error)
-		SearchProducts(ctx context.Context, query string, skip uint64, take uint64) ([]Product, error)
+		ListProducts(ctx context.Context, skip uint64, take uint64, categoryIDs []string, sort Sort) ([]Product, uint64, error)
+		ListProductsWithIDs(ctx context.Context, ids []string, variantIDs []string) ([]Product, error)
+		UpdateProduct(ctx context.Context, p Product, fields []string) error
+		ArchiveProduct(ctx context.Context, id string, archivedAt time.Time) error
+		SearchProducts(ctx context.Context, query string, skip uint64, take uint64, categoryIDs []string, sort Sort) ([]Product, uint64, error)
+		Search(ctx context.Context, q SearchQuery, categoryIDs []string) (*SearchResult, error)
+		PutCategory(ctx context.Context, c Category) error
+		ListCategories(ctx context.Context) ([]Category, error)
+		DeleteCategory(ctx context.Context, id string) error
+		RemoveCategoryFromProducts(ctx context.Context, id string) error
+		ReserveStock(ctx context.Context, holdID string, item StockItem) error
+		ReleaseStock(ctx context.Context, holdID string, item StockItem) error
+		CommitStock(ctx context.Context, holdID string, item StockItem) error
+		PutReservation(ctx context.Context, r Reservation) error
+		GetReservation(ctx context.Context, id string) (*Reservation, error)
+		SettleReservation(ctx context.Context, id string, status ReservationStatus) error
+		ConfirmReservation(ctx context.Context, id string) error
+		ListExpiredReservations(ctx context.Context, before time.Time, take uint64) ([]Reservation, error)
+		ClaimIdempotencyKey(ctx context.Context, key string, fingerprint string, productID string) error
+		GetIdempotencyKey(ctx context.Context, key string) (productID string, fingerprint string, err error)
+		DeleteIdempotencyKey(ctx context.Context, key string) error
 	}
 
 	elasticRepository struct {
@@ -30,9 +54,44 @@ type (
 	}
 
 	productDocument struct {
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		Price       float64 `json:"price"`
+		// ID repeats the document ID for sorting, which _id does not allow
+		ID          string `json:"id,omitempty"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		PriceAmount int64  `json:"price_amount"`
+		Currency    string `json:"currency"`
+		Stock       uint32 `json:"stock"`
+		Reserved    uint32 `json:"reserved"`
+		TaxCategory string `json:"tax_category,omitempty"`
+
+		CategoryIDs []string          `json:"category_ids,omitempty"`
+		Options     []Option          `json:"options,omitempty"`
+		Variants    []variantDocument `json:"variants,omitempty"`
+		Attributes  []OptionValue     `json:"attributes,omitempty"`
+		CreatedAt   *time.Time        `json:"created_at,omitempty"`
+		ArchivedAt  *time.Time        `json:"archived_at,omitempty"`
+
+		// LegacyPrice is the float price written before prices were kept
+		// in minor units. It is only read, never written.
+		LegacyPrice float64 `json:"price,omitempty"`
+	}
+
+	// variantDocument is a variant as stored in the nested variants field
+	// of its product.
+	variantDocument struct {
+		ID          string        `json:"id"`
+		SKU         string        `json:"sku"`
+		Options     []OptionValue `json:"options"`
+		PriceAmount int64         `json:"price_amount"`
+		Currency    string        `json:"currency"`
+		Stock       uint32        `json:"stock"`
+		Reserved    uint32        `json:"reserved"`
+	}
+
+	idempotencyDocument struct {
+		Fingerprint string    `json:"fingerprint"`
+		ProductID   string    `json:"product_id"`
+		ExpiresAt   time.Time `json:"expires_at"`
 	}
 )
 
@@ -46,9 +105,14 @@ func NewElasticRepository(url string) (CatalogRepository, error) {
 		return nil, err
 	}
 
-	return &elasticRepository{
+	r := &elasticRepository{
 		client: client,
-	}, nil
+	}
+	if err := bootstrapIndex(context.Background(), client); err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
 func (r *elasticRepository) Close() {
@@ -66,9 +130,21 @@ func (r *elasticRepository) PutProduct(
 	p Product,
 ) error {
 	doc := productDocument{
+		ID:          p.ID,
 		Name:        p.Name,
 		Description: p.Description,
-		Price:       p.Price,
+		PriceAmount: p.Price.Amount,
+		Currency:    p.Price.Currency,
+		Stock:       p.Stock,
+		Reserved:    p.Reserved,
+		TaxCategory: p.TaxCategory,
+		CategoryIDs: p.CategoryIDs,
+		Options:     p.Options,
+		Variants:    variantDocuments(p.Variants),
+		Attributes:  p.attributes(),
+	}
+	if !p.CreatedAt.IsZero() {
+		doc.CreatedAt = &p.CreatedAt
 	}
 
 	// serialize document to JSON
@@ -129,35 +205,66 @@ func (r *elasticRepository) GetProductByID(
 		return nil, fmt.Errorf("failed to parse document: %w", err)
 	}
 
-	return &Product{
-		ID:          id,
-		Name:        doc.Source.Name,
-		Description: doc.Source.Description,
-		Price:       doc.Source.Price,
-	}, nil
+	product := doc.Source.product(id)
+	return &product, nil
 }
 
 func (r *elasticRepository) ListProducts(
 	ctx context.Context,
 	skip uint64,
 	take uint64,
+	categoryIDs []string,
+	sort Sort,
+) ([]Product, uint64, error) {
+	return r.searchProducts(ctx, map[string]interface{}{
+		"query": productQuery(nil, categoryIDs),
+		"sort":  productSort(sort, false),
+		"from":  skip,
+		"size":  take,
+	})
+}
+
+func (r *elasticRepository) ListProductsWithIDs(
+	ctx context.Context,
+	ids []string,
+	variantIDs []string,
 ) ([]Product, error) {
-	// build search query
+	should := []interface{}{}
+	if len(ids) > 0 {
+		should = append(should, map[string]interface{}{
+			"ids": map[string]interface{}{
+				"values": ids,
+			},
+		})
+	}
+	if len(variantIDs) > 0 {
+		should = append(should, map[string]interface{}{
+			"nested": map[string]interface{}{
+				"path": "variants",
+				"query": map[string]interface{}{
+					"terms": map[string]interface{}{
+						"variants.id": variantIDs,
+					},
+				},
+			},
+		})
+	}
+
 	query := map[string]interface{}{
 		"query": map[string]interface{}{
-			"match_all": map[string]interface{}{},
+			"bool": map[string]interface{}{
+				"should": should,
+			},
 		},
-		"from": skip,
-		"size": take,
+		// Search returns 10 hits unless told otherwise
+		"size": len(ids) + len(variantIDs),
 	}
 
-	// Serialize the query to JSON
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(query); err != nil {
 		return nil, fmt.Errorf("failed to encode search query: %w", err)
 	}
 
-	// Perform the search
 	res, err := r.client.Search(
 		r.client.Search.WithContext(ctx),
 		r.client.Search.WithIndex("catalog"),
@@ -188,32 +295,44 @@ func (r *elasticRepository) ListProducts(
 
 	products := make([]Product, 0, len(searchResult.Hits.Hits))
 	for _, hit := range searchResult.Hits.Hits {
-		products = append(products, Product{
-			ID:          hit.ID,
-			Name:        hit.Source.Name,
-			Description: hit.Source.Description,
-			Price:       hit.Source.Price,
-		})
+		products = append(products, hit.Source.product(hit.ID))
 	}
 
 	return products, nil
 }
 
-func (r *elasticRepository) ListProductsWithIDs(
+func (r *elasticRepository) SearchProducts(
 	ctx context.Context,
-	ids []string,
-) ([]Product, error) {
-	query := map[string]interface{}{
-		"query": map[string]interface{}{
-			"ids": map[string]interface{}{
-				"values": ids,
+	query string,
+	skip uint64,
+	take uint64,
+	categoryIDs []string,
+	sort Sort,
+) ([]Product, uint64, error) {
+	return r.searchProducts(ctx, map[string]interface{}{
+		"query": productQuery(map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  query,
+				"fields": []string{"name", "description"},
 			},
-		},
-	}
+		}, categoryIDs),
+		"sort": productSort(sort, true),
+		"from": skip,
+		"size": take,
+	})
+}
+
+// searchProducts runs a product search and returns the products found and
+// the number of all matching products.
+func (r *elasticRepository) searchProducts(
+	ctx context.Context,
+	query map[string]interface{},
+) ([]Product, uint64, error) {
+	query["track_total_hits"] = true
 
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(query); err != nil {
-		return nil, fmt.Errorf("failed to encode search query: %w", err)
+		return nil, 0, fmt.Errorf("failed to encode search query: %w", err)
 	}
 
 	res, err := r.client.Search(
@@ -222,17 +341,20 @@ func (r *elasticRepository) ListProductsWithIDs(
 		r.client.Search.WithBody(&buf),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute search: %w", err)
+		return nil, 0, fmt.Errorf("failed to execute search: %w", err)
 	}
 
 	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("search query failed with status: %s", res.Status())
+		return nil, 0, fmt.Errorf("search query failed with status: %s", res.Status())
 	}
 
 	var searchResult struct {
 		Hits struct {
+			Total struct {
+				Value uint64 `json:"value"`
+			} `json:"total"`
 			Hits []struct {
 				ID     string          `json:"_id"`
 				Source productDocument `json:"_source"`
@@ -241,37 +363,127 @@ func (r *elasticRepository) ListProductsWithIDs(
 	}
 
 	if err := json.NewDecoder(res.Body).Decode(&searchResult); err != nil {
-		return nil, fmt.Errorf("failed to parse search response: %w", err)
+		return nil, 0, fmt.Errorf("failed to parse search response: %w", err)
 	}
 
 	products := make([]Product, 0, len(searchResult.Hits.Hits))
 	for _, hit := range searchResult.Hits.Hits {
-		products = append(products, Product{
-			ID:          hit.ID,
-			Name:        hit.Source.Name,
-			Description: hit.Source.Description,
-			Price:       hit.Source.Price,
+		products = append(products, hit.Source.product(hit.ID))
+	}
+
+	return products, searchResult.Hits.Total.Value, nil
+}
+
+// productSort orders products by sort and then by ID, so that pages do not
+// overlap. Relevance only means something for text searches, listings
+// sorted by it show the newest products first.
+func productSort(sort Sort, text bool) []interface{} {
+	var clauses []interface{}
+	switch {
+	case sort == SortPriceAsc || sort == SortPriceDesc:
+		order := "asc"
+		if sort == SortPriceDesc {
+			order = "desc"
+		}
+		clauses = append(clauses, map[string]interface{}{
+			"price_amount": map[string]interface{}{"order": order},
+		})
+	case sort == SortName:
+		clauses = append(clauses, map[string]interface{}{
+			"name.sort": map[string]interface{}{"order": "asc"},
+		})
+	case sort == SortNewest || !text:
+		// Products written before creation times were kept come last
+		clauses = append(clauses, map[string]interface{}{
+			"created_at": map[string]interface{}{"order": "desc", "missing": "_last"},
+		})
+	default:
+		clauses = append(clauses, map[string]interface{}{
+			"_score": map[string]interface{}{"order": "desc"},
 		})
 	}
 
-	return products, nil
+	return append(clauses, map[string]interface{}{
+		"id": map[string]interface{}{"order": "asc"},
+	})
 }
 
-func (r *elasticRepository) SearchProducts(
+// Search runs q with its facets as aggregations. The facet filters of q
+// narrow the products returned, but each facet is counted over the
+// products matching all filters except its own, so that the other values
+// of a facet stay selectable.
+func (r *elasticRepository) Search(
 	ctx context.Context,
-	query string,
-	skip uint64,
-	take uint64,
-) ([]Product, error) {
-	searchQuery := map[string]interface{}{
-		"query": map[string]interface{}{
+	q SearchQuery,
+	categoryIDs []string,
+) (*SearchResult, error) {
+	var must interface{}
+	if q.Query != "" {
+		must = map[string]interface{}{
 			"multi_match": map[string]interface{}{
-				"query":  query,
+				"query":  q.Query,
 				"fields": []string{"name", "description"},
 			},
-		},
-		"from": skip,
-		"size": take,
+		}
+	}
+
+	filters := searchFilters(q, categoryIDs)
+	aggs := map[string]interface{}{
+		"categories": facetAggregation(filters, facetCategories, map[string]interface{}{
+			"values": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "category_ids",
+					"size":  MaxCategories,
+				},
+			},
+		}),
+		"attributes": facetAggregation(filters, "", attributesAggregation),
+		"prices": facetAggregation(filters, facetPrices, map[string]interface{}{
+			// Products with variants are sold at the variants' prices
+			"products": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"bool": map[string]interface{}{
+						"must_not": hasVariants,
+					},
+				},
+				"aggs": priceAggregation(""),
+			},
+			"variants": map[string]interface{}{
+				"nested": map[string]interface{}{
+					"path": "variants",
+				},
+				"aggs": priceAggregation("variants."),
+			},
+		}),
+		"availability": facetAggregation(filters, facetInStock, map[string]interface{}{
+			"in_stock": map[string]interface{}{
+				"filter": inStock,
+			},
+		}),
+	}
+	if len(q.Attributes) > 0 {
+		// The values of a selected attribute are counted without its own
+		// filter, one bucket per attribute
+		selected := map[string]interface{}{}
+		for _, a := range q.Attributes {
+			selected[a.Name] = filtersExcept(filters, attributeFacet(a.Name))
+		}
+		aggs["selected_attributes"] = map[string]interface{}{
+			"filters": map[string]interface{}{
+				"filters": selected,
+			},
+			"aggs": attributesAggregation,
+		}
+	}
+
+	searchQuery := map[string]interface{}{
+		"query":            productQuery(must, nil),
+		"post_filter":      filtersExcept(filters, ""),
+		"sort":             productSort(q.Sort, q.Query != ""),
+		"from":             q.Skip,
+		"size":             q.Take,
+		"track_total_hits": true,
+		"aggs":             aggs,
 	}
 
 	var buf bytes.Buffer
@@ -294,28 +506,1247 @@ func (r *elasticRepository) SearchProducts(
 		return nil, fmt.Errorf("search query failed with status: %s", res.Status())
 	}
 
+	type (
+		bucket struct {
+			Key      string `json:"key"`
+			DocCount uint64 `json:"doc_count"`
+		}
+		attributeBuckets struct {
+			Attributes struct {
+				Names struct {
+					Buckets []struct {
+						Key    string `json:"key"`
+						Values struct {
+							Buckets []struct {
+								Key      string `json:"key"`
+								Products struct {
+									DocCount uint64 `json:"doc_count"`
+								} `json:"products"`
+							} `json:"buckets"`
+						} `json:"values"`
+					} `json:"buckets"`
+				} `json:"names"`
+			} `json:"attributes"`
+		}
+		priceBuckets struct {
+			Currencies struct {
+				Buckets []struct {
+					Key string `json:"key"`
+					Min struct {
+						Value float64 `json:"value"`
+					} `json:"min"`
+					Max struct {
+						Value float64 `json:"value"`
+					} `json:"max"`
+				} `json:"buckets"`
+			} `json:"currencies"`
+		}
+	)
 	var searchResult struct {
 		Hits struct {
+			Total struct {
+				Value uint64 `json:"value"`
+			} `json:"total"`
 			Hits []struct {
 				ID     string          `json:"_id"`
 				Source productDocument `json:"_source"`
 			} `json:"hits"`
 		} `json:"hits"`
+		Aggregations struct {
+			Categories struct {
+				Values struct {
+					Buckets []bucket `json:"buckets"`
+				} `json:"values"`
+			} `json:"categories"`
+			Attributes         attributeBuckets `json:"attributes"`
+			SelectedAttributes struct {
+				Buckets map[string]attributeBuckets `json:"buckets"`
+			} `json:"selected_attributes"`
+			Prices struct {
+				Products priceBuckets `json:"products"`
+				Variants priceBuckets `json:"variants"`
+			} `json:"prices"`
+			Availability struct {
+				DocCount uint64 `json:"doc_count"`
+				InStock  bucket `json:"in_stock"`
+			} `json:"availability"`
+		} `json:"aggregations"`
 	}
 
 	if err := json.NewDecoder(res.Body).Decode(&searchResult); err != nil {
 		return nil, fmt.Errorf("failed to parse search response: %w", err)
 	}
 
-	products := make([]Product, 0, len(searchResult.Hits.Hits))
+	aggregations := searchResult.Aggregations
+	result := &SearchResult{
+		Products: make([]Product, 0, len(searchResult.Hits.Hits)),
+		Total:    searchResult.Hits.Total.Value,
+		Facets: Facets{
+			Categories: []FacetValue{},
+			Attributes: []AttributeFacet{},
+			Prices:     []PriceRange{},
+			InStock:    aggregations.Availability.InStock.DocCount,
+			OutOfStock: aggregations.Availability.DocCount - aggregations.Availability.InStock.DocCount,
+		},
+	}
 	for _, hit := range searchResult.Hits.Hits {
-		products = append(products, Product{
-			ID:          hit.ID,
-			Name:        hit.Source.Name,
-			Description: hit.Source.Description,
-			Price:       hit.Source.Price,
+		result.Products = append(result.Products, hit.Source.product(hit.ID))
+	}
+
+	for _, b := range aggregations.Categories.Values.Buckets {
+		result.Facets.Categories = append(result.Facets.Categories, FacetValue{Value: b.Key, Count: b.DocCount})
+	}
+
+	attributeValues := func(a attributeBuckets, name string) ([]FacetValue, bool) {
+		for _, n := range a.Attributes.Names.Buckets {
+			if n.Key != name {
+				continue
+			}
+			values := []FacetValue{}
+			for _, b := range n.Values.Buckets {
+				values = append(values, FacetValue{Value: b.Key, Count: b.Products.DocCount})
+			}
+			return values, true
+		}
+		return nil, false
+	}
+	seen := map[string]bool{}
+	for _, name := range aggregations.Attributes.Attributes.Names.Buckets {
+		buckets, ok := aggregations.SelectedAttributes.Buckets[name.Key]
+		if !ok {
+			buckets = aggregations.Attributes
+		}
+		values, _ := attributeValues(buckets, name.Key)
+		result.Facets.Attributes = append(result.Facets.Attributes, AttributeFacet{Name: name.Key, Values: values})
+		seen[name.Key] = true
+	}
+	// Selected attributes no product passing all filters has
+	for _, a := range q.Attributes {
+		values, ok := attributeValues(aggregations.SelectedAttributes.Buckets[a.Name], a.Name)
+		if ok && !seen[a.Name] {
+			result.Facets.Attributes = append(result.Facets.Attributes, AttributeFacet{Name: a.Name, Values: values})
+			seen[a.Name] = true
+		}
+	}
+
+	prices := map[string]*PriceRange{}
+	currencies := []string{}
+	for _, buckets := range []priceBuckets{aggregations.Prices.Products, aggregations.Prices.Variants} {
+		for _, b := range buckets.Currencies.Buckets {
+			low, high := money.New(int64(b.Min.Value), b.Key), money.New(int64(b.Max.Value), b.Key)
+			p, ok := prices[low.Currency]
+			if !ok {
+				prices[low.Currency] = &PriceRange{Min: low, Max: high}
+				currencies = append(currencies, low.Currency)
+				continue
+			}
+			if low.Amount < p.Min.Amount {
+				p.Min = low
+			}
+			if high.Amount > p.Max.Amount {
+				p.Max = high
+			}
+		}
+	}
+	for _, c := range currencies {
+		result.Facets.Prices = append(result.Facets.Prices, *prices[c])
+	}
+
+	return result, nil
+}
+
+// The facets the filters of a search narrow, see searchFilter.
+const (
+	facetCategories = "categories"
+	facetPrices     = "prices"
+	facetInStock    = "in_stock"
+)
+
+// attributeFacet names the facet of the option values of an attribute.
+func attributeFacet(name string) string {
+	return "attributes." + name
+}
+
+// searchFilter is a filter of a search and the facet it narrows, whose
+// counts leave it out.
+type searchFilter struct {
+	facet  string
+	clause interface{}
+}
+
+// searchFilters turns the category, price, attribute and availability
+// filters of q into query clauses.
+func searchFilters(q SearchQuery, categoryIDs []string) []searchFilter {
+	filters := []searchFilter{}
+
+	if len(categoryIDs) > 0 {
+		filters = append(filters, searchFilter{facetCategories, map[string]interface{}{
+			"terms": map[string]interface{}{
+				"category_ids": categoryIDs,
+			},
+		}})
+	}
+
+	if q.MinPrice != nil || q.MaxPrice != nil {
+		bounds := map[string]interface{}{}
+		currency := ""
+		if q.MinPrice != nil {
+			bounds["gte"] = q.MinPrice.Amount
+			currency = q.MinPrice.Currency
+		}
+		if q.MaxPrice != nil {
+			bounds["lte"] = q.MaxPrice.Amount
+			currency = q.MaxPrice.Currency
+		}
+		// Products with variants match if one of the variants is in range
+		filters = append(filters, searchFilter{facetPrices, map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []interface{}{
+					map[string]interface{}{
+						"bool": map[string]interface{}{
+							"must_not": hasVariants,
+							"filter":   priceRange("", currency, bounds),
+						},
+					},
+					map[string]interface{}{
+						"nested": map[string]interface{}{
+							"path": "variants",
+							"query": map[string]interface{}{
+								"bool": map[string]interface{}{
+									"filter": priceRange("variants.", currency, bounds),
+								},
+							},
+						},
+					},
+				},
+				"minimum_should_match": 1,
+			},
+		}})
+	}
+
+	for _, a := range q.Attributes {
+		filters = append(filters, searchFilter{attributeFacet(a.Name), map[string]interface{}{
+			"nested": map[string]interface{}{
+				"path": "attributes",
+				"query": map[string]interface{}{
+					"bool": map[string]interface{}{
+						"filter": []interface{}{
+							map[string]interface{}{
+								"term": map[string]interface{}{
+									"attributes.name": a.Name,
+								},
+							},
+							map[string]interface{}{
+								"terms": map[string]interface{}{
+									"attributes.value": a.Values,
+								},
+							},
+						},
+					},
+				},
+			},
+		}})
+	}
+
+	if q.InStock {
+		filters = append(filters, searchFilter{facetInStock, inStock})
+	}
+
+	return filters
+}
+
+// priceRange matches the documents whose price fields, under prefix, are
+// within bounds. Amounts are only comparable within a currency.
+func priceRange(prefix string, currency string, bounds map[string]interface{}) []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"term": map[string]interface{}{
+				prefix + "currency": currency,
+			},
+		},
+		map[string]interface{}{
+			"range": map[string]interface{}{
+				prefix + "price_amount": bounds,
+			},
+		},
+	}
+}
+
+// filtersExcept matches the documents that pass all filters but those of
+// facet.
+func filtersExcept(filters []searchFilter, facet string) map[string]interface{} {
+	clauses := []interface{}{}
+	for _, f := range filters {
+		if f.facet != facet {
+			clauses = append(clauses, f.clause)
+		}
+	}
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"filter": clauses,
+		},
+	}
+}
+
+// facetAggregation runs aggs over the documents that pass all filters but
+// those of facet.
+func facetAggregation(filters []searchFilter, facet string, aggs map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"filter": filtersExcept(filters, facet),
+		"aggs":   aggs,
+	}
+}
+
+// attributesAggregation counts the products by the values of each of their
+// attributes.
+var attributesAggregation = map[string]interface{}{
+	"attributes": map[string]interface{}{
+		"nested": map[string]interface{}{
+			"path": "attributes",
+		},
+		"aggs": map[string]interface{}{
+			"names": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "attributes.name",
+					"size":  100,
+				},
+				"aggs": map[string]interface{}{
+					"values": map[string]interface{}{
+						"terms": map[string]interface{}{
+							"field": "attributes.value",
+							"size":  MaxOptionValues,
+						},
+						// Count products rather than attributes
+						"aggs": map[string]interface{}{
+							"products": map[string]interface{}{
+								"reverse_nested": map[string]interface{}{},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// priceAggregation gives the lowest and highest price, under prefix, in
+// every currency.
+func priceAggregation(prefix string) map[string]interface{} {
+	return map[string]interface{}{
+		"currencies": map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field": prefix + "currency",
+			},
+			"aggs": map[string]interface{}{
+				"min": map[string]interface{}{
+					"min": map[string]interface{}{"field": prefix + "price_amount"},
+				},
+				"max": map[string]interface{}{
+					"max": map[string]interface{}{"field": prefix + "price_amount"},
+				},
+			},
+		},
+	}
+}
+
+// inStock matches the products without variants that have units left and
+// the products with a variant that has.
+var inStock = map[string]interface{}{
+	"bool": map[string]interface{}{
+		"should": []interface{}{
+			map[string]interface{}{
+				"bool": map[string]interface{}{
+					"must_not": hasVariants,
+					"filter":   availableScript(""),
+				},
+			},
+			map[string]interface{}{
+				"nested": map[string]interface{}{
+					"path":  "variants",
+					"query": availableScript("variants."),
+				},
+			},
+		},
+		"minimum_should_match": 1,
+	},
+}
+
+var hasVariants = map[string]interface{}{
+	"nested": map[string]interface{}{
+		"path": "variants",
+		"query": map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		},
+	},
+}
+
+// availableScript matches the documents whose stock field, under prefix,
+// exceeds their reserved field.
+func availableScript(prefix string) map[string]interface{} {
+	return map[string]interface{}{
+		"script": map[string]interface{}{
+			"script": map[string]interface{}{
+				"source": `doc[params.stock].size() > 0 && doc[params.stock].value > (doc[params.reserved].size() == 0 ? 0 : doc[params.reserved].value)`,
+				"lang":   "painless",
+				"params": map[string]interface{}{
+					"stock":    prefix + "stock",
+					"reserved": prefix + "reserved",
+				},
+			},
+		},
+	}
+}
+
+// archived matches the archived products, which listings leave out.
+var archived = map[string]interface{}{
+	"exists": map[string]interface{}{
+		"field": "archived_at",
+	},
+}
+
+// productQuery matches the products that match must, if given, are not
+// archived, match all filters and, if categoryIDs are given, are in one of
+// those categories.
+func productQuery(must interface{}, categoryIDs []string, filters ...interface{}) map[string]interface{} {
+	query := map[string]interface{}{
+		"must_not": archived,
+	}
+	if must != nil {
+		query["must"] = must
+	}
+	if len(categoryIDs) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"terms": map[string]interface{}{
+				"category_ids": categoryIDs,
+			},
 		})
 	}
+	if len(filters) > 0 {
+		query["filter"] = filters
+	}
+	return map[string]interface{}{"bool": query}
+}
 
-	return products, nil
+// UpdateProduct writes the given fields of p to the stored document. The
+// stock counters other requests change are left alone.
+func (r *elasticRepository) UpdateProduct(
+	ctx context.Context,
+	p Product,
+	fields []string,
+) error {
+	doc := map[string]interface{}{}
+	for _, field := range fields {
+		switch field {
+		case FieldName:
+			doc["name"] = p.Name
+		case FieldDescription:
+			doc["description"] = p.Description
+		case FieldPrice:
+			doc["price_amount"] = p.Price.Amount
+			doc["currency"] = p.Price.Currency
+		case FieldStock:
+			doc["stock"] = p.Stock
+		case FieldTaxCategory:
+			doc["tax_category"] = p.TaxCategory
+		case FieldCategoryIDs:
+			doc["category_ids"] = p.CategoryIDs
+		}
+	}
+
+	if !contains(fields, FieldVariants) {
+		return r.updateDocument(ctx, "catalog", p.ID, doc)
+	}
+
+	// Variants are replaced by a script that keeps the stock reserved for
+	// them since p was read
+	doc["attributes"] = p.attributes()
+	_, err := r.updateWithScript(ctx, "catalog", p.ID, updateVariantsScript, map[string]interface{}{
+		"doc":      doc,
+		"options":  p.Options,
+		"variants": variantDocuments(p.Variants),
+	})
+	return err
+}
+
+func (r *elasticRepository) ArchiveProduct(
+	ctx context.Context,
+	id string,
+	archivedAt time.Time,
+) error {
+	return r.updateDocument(ctx, "catalog", id, map[string]interface{}{
+		"archived_at": archivedAt,
+	})
+}
+
+// updateDocument merges doc into an existing document.
+func (r *elasticRepository) updateDocument(
+	ctx context.Context,
+	index string,
+	id string,
+	doc map[string]interface{},
+) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{"doc": doc}); err != nil {
+		return fmt.Errorf("failed to encode update: %w", err)
+	}
+
+	res, err := r.client.Update(
+		index,
+		id,
+		&buf,
+		r.client.Update.WithContext(ctx),
+		r.client.Update.WithRetryOnConflict(5),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return ErrNotFound
+	}
+
+	if res.IsError() {
+		return fmt.Errorf("failed to update document, status: %s", res.Status())
+	}
+
+	return nil
+}
+
+func (d productDocument) product(id string) Product {
+	price := money.New(d.PriceAmount, d.Currency)
+	if d.Currency == "" && d.PriceAmount == 0 && d.LegacyPrice != 0 {
+		price.Amount = int64(math.Round(d.LegacyPrice * math.Pow10(money.Exponent(price.Currency))))
+	}
+
+	taxCategory := d.TaxCategory
+	if taxCategory == "" {
+		taxCategory = tax.DefaultCategory
+	}
+
+	createdAt := time.Time{}
+	if d.CreatedAt != nil {
+		createdAt = *d.CreatedAt
+	}
+
+	return Product{
+		ID:          id,
+		Name:        d.Name,
+		Description: d.Description,
+		Price:       price,
+		Stock:       d.Stock,
+		Reserved:    d.Reserved,
+		TaxCategory: taxCategory,
+		CategoryIDs: d.CategoryIDs,
+		Options:     d.Options,
+		Variants:    d.variants(),
+		CreatedAt:   createdAt,
+		ArchivedAt:  d.ArchivedAt,
+	}
+}
+
+func (d productDocument) variants() []Variant {
+	if len(d.Variants) == 0 {
+		return nil
+	}
+
+	variants := []Variant{}
+	for _, v := range d.Variants {
+		variants = append(variants, Variant{
+			ID:       v.ID,
+			SKU:      v.SKU,
+			Options:  v.Options,
+			Price:    money.New(v.PriceAmount, v.Currency),
+			Stock:    v.Stock,
+			Reserved: v.Reserved,
+		})
+	}
+	return variants
+}
+
+func variantDocuments(variants []Variant) []variantDocument {
+	docs := []variantDocument{}
+	for _, v := range variants {
+		docs = append(docs, variantDocument{
+			ID:          v.ID,
+			SKU:         v.SKU,
+			Options:     v.Options,
+			PriceAmount: v.Price.Amount,
+			Currency:    v.Price.Currency,
+			Stock:       v.Stock,
+			Reserved:    v.Reserved,
+		})
+	}
+	return docs
+}
+
+const (
+	categoriesIndex = "catalog_categories"
+
+	removeCategoryScript = `
+		if (ctx._source.category_ids != null) {
+			ctx._source.category_ids.removeIf(id -> id == params.id);
+		}`
+)
+
+func (r *elasticRepository) PutCategory(
+	ctx context.Context,
+	c Category,
+) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(c); err != nil {
+		return fmt.Errorf("failed to encode category document: %w", err)
+	}
+
+	// Refresh so the next tree read sees the change
+	res, err := r.client.Index(
+		categoriesIndex,
+		&buf,
+		r.client.Index.WithContext(ctx),
+		r.client.Index.WithDocumentID(c.ID),
+		r.client.Index.WithRefresh("true"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to index category: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to index category, status: %s", res.Status())
+	}
+
+	return nil
+}
+
+func (r *elasticRepository) ListCategories(ctx context.Context) ([]Category, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{
+		"query": map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		},
+		"size": MaxCategories,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to encode search query: %w", err)
+	}
+
+	res, err := r.client.Search(
+		r.client.Search.WithContext(ctx),
+		r.client.Search.WithIndex(categoriesIndex),
+		r.client.Search.WithBody(&buf),
+		r.client.Search.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("search query failed with status: %s", res.Status())
+	}
+
+	var searchResult struct {
+		Hits struct {
+			Hits []struct {
+				Source Category `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&searchResult); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+
+	categories := make([]Category, 0, len(searchResult.Hits.Hits))
+	for _, hit := range searchResult.Hits.Hits {
+		categories = append(categories, hit.Source)
+	}
+
+	return categories, nil
+}
+
+func (r *elasticRepository) DeleteCategory(
+	ctx context.Context,
+	id string,
+) error {
+	res, err := r.client.Delete(
+		categoriesIndex,
+		id,
+		r.client.Delete.WithContext(ctx),
+		r.client.Delete.WithRefresh("true"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return ErrNotFound
+	}
+
+	if res.IsError() {
+		return fmt.Errorf("failed to delete category, status: %s", res.Status())
+	}
+
+	return nil
+}
+
+// RemoveCategoryFromProducts takes the category off every product in it.
+func (r *elasticRepository) RemoveCategoryFromProducts(
+	ctx context.Context,
+	id string,
+) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{
+				"category_ids": id,
+			},
+		},
+		"script": map[string]interface{}{
+			"source": removeCategoryScript,
+			"lang":   "painless",
+			"params": map[string]interface{}{"id": id},
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to encode update: %w", err)
+	}
+
+	res, err := r.client.UpdateByQuery(
+		[]string{"catalog"},
+		r.client.UpdateByQuery.WithContext(ctx),
+		r.client.UpdateByQuery.WithBody(&buf),
+		r.client.UpdateByQuery.WithRefresh(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update products: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to update products, status: %s", res.Status())
+	}
+
+	return nil
+}
+
+const (
+	reservationsIndex    = "catalog_reservations"
+	idempotencyKeysIndex = "catalog_idempotency_keys"
+)
+
+const (
+	// Stock counters are changed with scripts so that concurrent orders for
+	// the same product cannot both take the last unit. The target is the
+	// product itself or, given a variant_id, that variant; unknown variants
+	// are left alone. Given a hold_id, the reserved quantity is also kept
+	// in the target's holds under it, and releasing or committing only
+	// takes back what is still held.
+	stockTarget = `
+		def target = ctx._source;
+		if (params.variant_id != '') {
+			target = null;
+			if (ctx._source.variants != null) {
+				for (def v : ctx._source.variants) {
+					if (v.id == params.variant_id) {
+						target = v;
+					}
+				}
+			}
+		}`
+	reserveStockScript = stockTarget + `
+		if (target == null) {
+			ctx.op = 'noop';
+			return;
+		}
+		long stock = target.stock == null ? 0 : target.stock;
+		long reserved = target.reserved == null ? 0 : target.reserved;
+		if (stock - reserved < params.quantity) {
+			ctx.op = 'noop';
+			return;
+		}
+		target.reserved = reserved + params.quantity;
+		if (params.hold_id != '') {
+			if (target.holds == null) {
+				target.holds = new HashMap();
+			}
+			target.holds[params.hold_id] = params.quantity;
+		}`
+	stockHold = `
+		if (target == null) {
+			ctx.op = 'noop';
+			return;
+		}
+		long quantity = params.quantity;
+		if (params.hold_id != '') {
+			if (target.holds == null || !target.holds.containsKey(params.hold_id)) {
+				ctx.op = 'noop';
+				return;
+			}
+			quantity = ((Number) target.holds.remove(params.hold_id)).longValue();
+		}`
+	releaseStockScript = stockTarget + stockHold + `
+		long reserved = target.reserved == null ? 0 : target.reserved;
+		target.reserved = Math.max(0, reserved - quantity);`
+	commitStockScript = stockTarget + stockHold + `
+		long stock = target.stock == null ? 0 : target.stock;
+		long reserved = target.reserved == null ? 0 : target.reserved;
+		target.stock = Math.max(0, stock - quantity);
+		target.reserved = Math.max(0, reserved - quantity);`
+	updateVariantsScript = `
+		Map reserved = new HashMap();
+		Map holds = new HashMap();
+		if (ctx._source.variants != null) {
+			for (def v : ctx._source.variants) {
+				reserved.put(v.id, v.reserved);
+				holds.put(v.id, v.holds);
+			}
+		}
+		for (def entry : params.doc.entrySet()) {
+			ctx._source[entry.getKey()] = entry.getValue();
+		}
+		List variants = new ArrayList();
+		for (def v : params.variants) {
+			Map variant = new HashMap(v);
+			def r = reserved.get(v.id);
+			variant.reserved = r == null ? 0 : r;
+			if (holds.get(v.id) != null) {
+				variant.holds = holds.get(v.id);
+			}
+			variants.add(variant);
+		}
+		ctx._source.options = params.options;
+		ctx._source.variants = variants;`
+	settleReservationScript = `
+		if (ctx._source.status != 'reserved') {
+			ctx.op = 'noop';
+		} else {
+			ctx._source.status = params.status;
+		}`
+	confirmReservationScript = `
+		if (ctx._source.status != 'reserved') {
+			ctx.op = 'noop';
+		} else {
+			ctx._source.remove('expires_at');
+		}`
+)
+
+func (r *elasticRepository) ReserveStock(
+	ctx context.Context,
+	holdID string,
+	item StockItem,
+) error {
+	updated, err := r.updateWithScript(ctx, "catalog", item.ProductID, reserveStockScript, stockParams(holdID, item))
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return &InsufficientStockError{ProductID: item.ProductID, VariantID: item.VariantID}
+	}
+
+	return nil
+}
+
+func (r *elasticRepository) ReleaseStock(
+	ctx context.Context,
+	holdID string,
+	item StockItem,
+) error {
+	_, err := r.updateWithScript(ctx, "catalog", item.ProductID, releaseStockScript, stockParams(holdID, item))
+	return err
+}
+
+func (r *elasticRepository) CommitStock(
+	ctx context.Context,
+	holdID string,
+	item StockItem,
+) error {
+	_, err := r.updateWithScript(ctx, "catalog", item.ProductID, commitStockScript, stockParams(holdID, item))
+	return err
+}
+
+func stockParams(holdID string, item StockItem) map[string]interface{} {
+	return map[string]interface{}{
+		"hold_id":    holdID,
+		"variant_id": item.VariantID,
+		"quantity":   item.Quantity,
+	}
+}
+
+func (r *elasticRepository) PutReservation(
+	ctx context.Context,
+	reservation Reservation,
+) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(reservation); err != nil {
+		return fmt.Errorf("failed to encode reservation document: %w", err)
+	}
+
+	res, err := r.client.Index(
+		reservationsIndex,
+		&buf,
+		r.client.Index.WithContext(ctx),
+		r.client.Index.WithDocumentID(reservation.ID),
+		r.client.Index.WithRefresh("true"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to index reservation: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to index reservation, status: %s", res.Status())
+	}
+
+	return nil
+}
+
+func (r *elasticRepository) GetReservation(
+	ctx context.Context,
+	id string,
+) (*Reservation, error) {
+	res, err := r.client.Get(
+		reservationsIndex,
+		id,
+		r.client.Get.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return nil, ErrNotFound
+	}
+
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to get reservation, status: %s", res.Status())
+	}
+
+	var doc struct {
+		Source Reservation `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse reservation: %w", err)
+	}
+
+	return &doc.Source, nil
+}
+
+func (r *elasticRepository) SettleReservation(
+	ctx context.Context,
+	id string,
+	status ReservationStatus,
+) error {
+	updated, err := r.updateWithScript(ctx, reservationsIndex, id, settleReservationScript, map[string]interface{}{
+		"status": status,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return ErrReservationSettled
+	}
+
+	return nil
+}
+
+func (r *elasticRepository) ConfirmReservation(
+	ctx context.Context,
+	id string,
+) error {
+	updated, err := r.updateWithScript(ctx, reservationsIndex, id, confirmReservationScript, map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return ErrReservationSettled
+	}
+
+	return nil
+}
+
+// ListExpiredReservations returns up to take reservations, the longest
+// expired first, that still hold stock and expired before before.
+func (r *elasticRepository) ListExpiredReservations(
+	ctx context.Context,
+	before time.Time,
+	take uint64,
+) ([]Reservation, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{
+						"term": map[string]interface{}{
+							"status.keyword": ReservationReserved,
+						},
+					},
+					map[string]interface{}{
+						"range": map[string]interface{}{
+							"expires_at": map[string]interface{}{"lt": before},
+						},
+					},
+				},
+			},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{
+				"expires_at": map[string]interface{}{"order": "asc", "unmapped_type": "date"},
+			},
+		},
+		"size": take,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to encode search query: %w", err)
+	}
+
+	res, err := r.client.Search(
+		r.client.Search.WithContext(ctx),
+		r.client.Search.WithIndex(reservationsIndex),
+		r.client.Search.WithBody(&buf),
+		r.client.Search.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("search query failed with status: %s", res.Status())
+	}
+
+	var searchResult struct {
+		Hits struct {
+			Hits []struct {
+				Source Reservation `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&searchResult); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+
+	reservations := make([]Reservation, 0, len(searchResult.Hits.Hits))
+	for _, hit := range searchResult.Hits.Hits {
+		reservations = append(reservations, hit.Source)
+	}
+
+	return reservations, nil
+}
+
+// ClaimIdempotencyKey records the product about to be created for key. An
+// expired key is taken over, a live one fails with ErrIdempotencyKeyClaimed.
+func (r *elasticRepository) ClaimIdempotencyKey(
+	ctx context.Context,
+	key string,
+	fingerprint string,
+	productID string,
+) error {
+	doc := idempotencyDocument{
+		Fingerprint: fingerprint,
+		ProductID:   productID,
+		ExpiresAt:   time.Now().Add(idempotency.TTL),
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode idempotency key: %w", err)
+	}
+	body := buf.Bytes()
+
+	res, err := r.client.Index(
+		idempotencyKeysIndex,
+		bytes.NewReader(body),
+		r.client.Index.WithContext(ctx),
+		r.client.Index.WithDocumentID(key),
+		r.client.Index.WithOpType("create"),
+		r.client.Index.WithRefresh("true"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to index idempotency key: %w", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != 409 {
+		if res.IsError() {
+			return fmt.Errorf("failed to index idempotency key, status: %s", res.Status())
+		}
+		return nil
+	}
+
+	// The key exists; only replace it if it has expired and nobody else
+	// replaced it first
+	existing, seqNo, primaryTerm, err := r.getIdempotencyDocument(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return ErrIdempotencyKeyClaimed
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ExpiresAt.After(time.Now()) {
+		return ErrIdempotencyKeyClaimed
+	}
+
+	res, err = r.client.Index(
+		idempotencyKeysIndex,
+		bytes.NewReader(body),
+		r.client.Index.WithContext(ctx),
+		r.client.Index.WithDocumentID(key),
+		r.client.Index.WithIfSeqNo(seqNo),
+		r.client.Index.WithIfPrimaryTerm(primaryTerm),
+		r.client.Index.WithRefresh("true"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to index idempotency key: %w", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode == 409 {
+		return ErrIdempotencyKeyClaimed
+	}
+	if res.IsError() {
+		return fmt.Errorf("failed to index idempotency key, status: %s", res.Status())
+	}
+
+	return nil
+}
+
+func (r *elasticRepository) GetIdempotencyKey(
+	ctx context.Context,
+	key string,
+) (string, string, error) {
+	doc, _, _, err := r.getIdempotencyDocument(ctx, key)
+	if err != nil {
+		return "", "", err
+	}
+
+	if doc.ExpiresAt.Before(time.Now()) {
+		return "", "", ErrNotFound
+	}
+
+	return doc.ProductID, doc.Fingerprint, nil
+}
+
+func (r *elasticRepository) DeleteIdempotencyKey(
+	ctx context.Context,
+	key string,
+) error {
+	res, err := r.client.Delete(
+		idempotencyKeysIndex,
+		key,
+		r.client.Delete.WithContext(ctx),
+		r.client.Delete.WithRefresh("true"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() && res.StatusCode != 404 {
+		return fmt.Errorf("failed to delete idempotency key, status: %s", res.Status())
+	}
+
+	return nil
+}
+
+func (r *elasticRepository) getIdempotencyDocument(
+	ctx context.Context,
+	key string,
+) (*idempotencyDocument, int, int, error) {
+	res, err := r.client.Get(
+		idempotencyKeysIndex,
+		key,
+		r.client.Get.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return nil, 0, 0, ErrNotFound
+	}
+
+	if res.IsError() {
+		return nil, 0, 0, fmt.Errorf("failed to get idempotency key, status: %s", res.Status())
+	}
+
+	var doc struct {
+		SeqNo       int                 `json:"_seq_no"`
+		PrimaryTerm int                 `json:"_primary_term"`
+		Source      idempotencyDocument `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to parse idempotency key: %w", err)
+	}
+
+	return &doc.Source, doc.SeqNo, doc.PrimaryTerm, nil
+}
+
+// updateWithScript runs a painless script against a single document and
+// reports whether the script changed it, i.e. did not set ctx.op to noop.
+func (r *elasticRepository) updateWithScript(
+	ctx context.Context,
+	index string,
+	id string,
+	script string,
+	params map[string]interface{},
+) (bool, error) {
+	body := map[string]interface{}{
+		"script": map[string]interface{}{
+			"source": script,
+			"lang":   "painless",
+			"params": params,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return false, fmt.Errorf("failed to encode update: %w", err)
+	}
+
+	res, err := r.client.Update(
+		index,
+		id,
+		&buf,
+		r.client.Update.WithContext(ctx),
+		r.client.Update.WithRetryOnConflict(5),
+		r.client.Update.WithTimeout(5*time.Second),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update document: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return false, ErrNotFound
+	}
+
+	if res.IsError() {
+		return false, fmt.Errorf("failed to update document, status: %s", res.Status())
+	}
+
+	var result struct {
+		Result string `json:"result"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to parse update response: %w", err)
+	}
+
+	return result.Result != "noop", nil
 }
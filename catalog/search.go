@@ -0,0 +1,148 @@
+package catalog
+
+import (
+	"fmt"
+
+	pb "github.com/stiffinWanjohi/go-ecommerce/catalog/pb"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
+)
+
+// Sort is the order products are listed in. Every order falls back to
+// the product IDs for products that compare equal.
+type Sort string
+
+const (
+	// SortRelevance lists the best matches of a text search first and the
+	// newest products first otherwise. It is the default.
+	SortRelevance Sort = "relevance"
+	SortPriceAsc  Sort = "price_asc"
+	SortPriceDesc Sort = "price_desc"
+	SortNewest    Sort = "newest"
+	SortName      Sort = "name"
+)
+
+var sorts = []Sort{SortRelevance, SortPriceAsc, SortPriceDesc, SortNewest, SortName}
+
+type (
+	// SearchQuery narrows a product search. Different kinds of filters all
+	// apply, while the categories given and the values given for one
+	// attribute are alternatives.
+	SearchQuery struct {
+		Query string
+
+		// Categories are IDs or slugs, their subcategories included
+		Categories []string
+		MinPrice   *money.Money
+		MaxPrice   *money.Money
+		Attributes []AttributeFilter
+		InStock    bool
+
+		Sort Sort
+		Skip uint64
+		Take uint64
+	}
+
+	// AttributeFilter matches the products with a variant that has one of
+	// Values for the option Name.
+	AttributeFilter struct {
+		Name   string
+		Values []string
+	}
+
+	SearchResult struct {
+		Products []Product
+		Total    uint64
+		Facets   Facets
+	}
+
+	// Facets count the products matching a search by category, by option
+	// value and by availability, and give their price range in every
+	// currency they are priced in. Each facet leaves out its own filters,
+	// so that selecting a value does not hide the others.
+	Facets struct {
+		Categories []FacetValue
+		Attributes []AttributeFacet
+		Prices     []PriceRange
+		InStock    uint64
+		OutOfStock uint64
+	}
+
+	FacetValue struct {
+		Value string
+		Count uint64
+	}
+
+	AttributeFacet struct {
+		Name   string
+		Values []FacetValue
+	}
+
+	PriceRange struct {
+		Min money.Money
+		Max money.Money
+	}
+)
+
+func (s Sort) Validate() error {
+	v := &validation.Error{}
+	valid := s == ""
+	for _, sort := range sorts {
+		valid = valid || s == sort
+	}
+	v.Check(valid, "sort", "must be one of %v", sorts)
+	return v.Err()
+}
+
+var sortToProto = map[Sort]pb.ProductSort{
+	SortRelevance: pb.ProductSort_PRODUCT_SORT_RELEVANCE,
+	SortPriceAsc:  pb.ProductSort_PRODUCT_SORT_PRICE_ASC,
+	SortPriceDesc: pb.ProductSort_PRODUCT_SORT_PRICE_DESC,
+	SortNewest:    pb.ProductSort_PRODUCT_SORT_NEWEST,
+	SortName:      pb.ProductSort_PRODUCT_SORT_NAME,
+}
+
+func (s Sort) Proto() pb.ProductSort {
+	return sortToProto[s]
+}
+
+func SortFromProto(p pb.ProductSort) Sort {
+	for s, sp := range sortToProto {
+		if sp == p {
+			return s
+		}
+	}
+	return ""
+}
+
+func (q SearchQuery) Validate() error {
+	v := &validation.Error{}
+	v.Merge("", q.Sort.Validate())
+	v.Check(q.MinPrice == nil || !q.MinPrice.IsNegative(), "minPrice.amount", "must not be negative")
+	v.Check(q.MaxPrice == nil || !q.MaxPrice.IsNegative(), "maxPrice.amount", "must not be negative")
+	if q.MinPrice != nil && q.MaxPrice != nil {
+		v.Check(q.MinPrice.Currency == q.MaxPrice.Currency, "maxPrice.currency", "must be the currency of minPrice %s", q.MinPrice.Currency)
+		v.Check(q.MinPrice.Amount <= q.MaxPrice.Amount, "maxPrice.amount", "must not be less than minPrice")
+	}
+	for i, a := range q.Attributes {
+		v.Check(a.Name != "", fmt.Sprintf("attributes[%d].name", i), "is required")
+		v.Check(len(a.Values) > 0, fmt.Sprintf("attributes[%d].values", i), "must have at least one value")
+	}
+	return v.Err()
+}
+
+// attributes lists the option values the variants of p come in, once
+// each, for searches to filter and count products by.
+func (p Product) attributes() []OptionValue {
+	seen := map[OptionValue]bool{}
+	attributes := []OptionValue{}
+	for _, variant := range p.Variants {
+		for _, o := range variant.Options {
+			if !seen[o] {
+				seen[o] = true
+				attributes = append(attributes, o)
+			}
+		}
+	}
+	return attributes
+}
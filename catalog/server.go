@@ -2,26 +2,79 @@ package catalog
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net"
+	"strings"
 
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
 	pb "github.com/stiffinWanjohi/go-ecommerce/catalog/pb"
+	"github.com/stiffinWanjohi/go-ecommerce/grpcerr"
+	"github.com/stiffinWanjohi/go-ecommerce/idempotency"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
 )
 
+const (
+	reasonProductNotFound     = "PRODUCT_NOT_FOUND"
+	reasonProductArchived     = "PRODUCT_ARCHIVED"
+	reasonCategoryNotFound    = "CATEGORY_NOT_FOUND"
+	reasonCategoryHasChildren = "CATEGORY_HAS_CHILDREN"
+	reasonReservationNotFound = "RESERVATION_NOT_FOUND"
+	reasonReservationSettled  = "RESERVATION_SETTLED"
+	reasonInsufficientStock   = "INSUFFICIENT_STOCK"
+)
+
+// reasons maps the reasons the server reports back to the errors of this
+// package. Insufficient stock is turned into an *InsufficientStockError by
+// the client instead.
+var reasons = grpcerr.Reasons{
+	reasonProductNotFound:        ErrNotFound,
+	reasonProductArchived:        ErrProductArchived,
+	reasonCategoryNotFound:       ErrCategoryNotFound,
+	reasonCategoryHasChildren:    ErrCategoryHasChildren,
+	reasonReservationNotFound:    ErrNotFound,
+	reasonReservationSettled:     ErrReservationSettled,
+	idempotency.ReasonConflict:   idempotency.ErrConflict,
+	idempotency.ReasonInProgress: idempotency.ErrInProgress,
+}
+
 type grpcServer struct {
 	pb.UnimplementedCatalogServiceServer
 	catalogService CatalogService
 }
 
-func ListenGRPC(s CatalogService, port int) error {
+var policy = auth.Policy{
+	pb.CatalogService_PostProduct_FullMethodName:            auth.RequireRole(auth.RoleAdmin),
+	pb.CatalogService_GetProduct_FullMethodName:             auth.Public,
+	pb.CatalogService_GetProducts_FullMethodName:            auth.Public,
+	pb.CatalogService_SearchProducts_FullMethodName:         auth.Public,
+	pb.CatalogService_UpdateProduct_FullMethodName:          auth.RequireRole(auth.RoleAdmin),
+	pb.CatalogService_DeleteProduct_FullMethodName:          auth.RequireRole(auth.RoleAdmin),
+	pb.CatalogService_PostCategory_FullMethodName:           auth.RequireRole(auth.RoleAdmin),
+	pb.CatalogService_GetCategories_FullMethodName:          auth.Public,
+	pb.CatalogService_UpdateCategory_FullMethodName:         auth.RequireRole(auth.RoleAdmin),
+	pb.CatalogService_DeleteCategory_FullMethodName:         auth.RequireRole(auth.RoleAdmin),
+	pb.CatalogService_ReserveStock_FullMethodName:           auth.RequireRole(auth.RoleService),
+	pb.CatalogService_ReleaseStock_FullMethodName:           auth.RequireRole(auth.RoleService),
+	pb.CatalogService_CommitStock_FullMethodName:            auth.RequireRole(auth.RoleService),
+	pb.CatalogService_ConfirmReservation_FullMethodName:     auth.RequireRole(auth.RoleService),
+	pb.CatalogService_GetExpiredReservations_FullMethodName: auth.RequireRole(auth.RoleService),
+}
+
+func ListenGRPC(s CatalogService, signer *auth.Signer, port int) error {
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return err
 	}
 
-	serv := grpc.NewServer()
+	serv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcerr.UnaryServerInterceptor(),
+		auth.UnaryServerInterceptor(signer, policy),
+	))
 	pb.RegisterCatalogServiceServer(serv, &grpcServer{
 		catalogService: s,
 	})
@@ -34,22 +87,28 @@ func (s *grpcServer) PostProduct(
 	r *pb.PostProductRequest,
 ) (*pb.PostProductResponse, error) {
 	product := Product{
-		Name:        r.Name,
-		Description: r.Description,
-		Price:       r.Price,
+		Name:           r.Name,
+		Description:    r.Description,
+		Price:          money.FromProto(r.Price),
+		Stock:          r.Stock,
+		TaxCategory:    r.TaxCategory,
+		CategoryIDs:    r.CategoryIds,
+		Options:        optionsFromProto(r.Options),
+		Variants:       variantsFromProto(r.Variants),
+		IdempotencyKey: r.IdempotencyKey,
 	}
 	p, err := s.catalogService.PostProduct(ctx, product)
-	if err != nil {
+	switch {
+	case errors.Is(err, idempotency.ErrConflict):
+		return nil, grpcerr.New(codes.AlreadyExists, idempotency.ReasonConflict, err.Error())
+	case errors.Is(err, idempotency.ErrInProgress):
+		return nil, grpcerr.New(codes.Aborted, idempotency.ReasonInProgress, err.Error())
+	case err != nil:
 		return nil, err
 	}
 
 	return &pb.PostProductResponse{
-		Product: &pb.Product{
-			Id:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-		},
+		Product: productToProto(p),
 	}, nil
 }
 
@@ -58,17 +117,15 @@ func (s *grpcServer) GetProduct(
 	r *pb.GetProductRequest,
 ) (*pb.GetProductResponse, error) {
 	p, err := s.catalogService.GetProduct(ctx, r.Id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, grpcerr.New(codes.NotFound, reasonProductNotFound, fmt.Sprintf("product %s not found", r.Id))
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return &pb.GetProductResponse{
-		Product: &pb.Product{
-			Id:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-		},
+		Product: productToProto(p),
 	}, nil
 }
 
@@ -77,30 +134,403 @@ func (s *grpcServer) GetProducts(
 	r *pb.GetProductsRequest,
 ) (*pb.GetProductsResponse, error) {
 	var products []Product
+	var total uint64
 	var err error
-	if len(r.Ids) > 0 {
-		products, err = s.catalogService.GetProductsByIDs(ctx, r.Ids)
+	if len(r.Ids) > 0 || len(r.VariantIds) > 0 {
+		products, err = s.catalogService.GetProductsByIDs(ctx, r.Ids, r.VariantIds)
+		total = uint64(len(products))
 	} else if r.Query != "" {
-		products, err = s.catalogService.SearchProducts(ctx, r.Query, r.Skip, r.Take)
+		products, total, err = s.catalogService.SearchProducts(ctx, r.Query, r.Skip, r.Take, r.Category, SortFromProto(r.Sort))
 	} else {
-		products, err = s.catalogService.GetProducts(ctx, r.Skip, r.Take)
+		products, total, err = s.catalogService.GetProducts(ctx, r.Skip, r.Take, r.Category, SortFromProto(r.Sort))
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, categoryError(r.Category, err)
 	}
 
 	pbProducts := make([]*pb.Product, len(products))
 	for index, p := range products {
-		pbProducts[index] = &pb.Product{
-			Id:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-		}
+		pbProducts[index] = productToProto(&p)
 	}
 
 	return &pb.GetProductsResponse{
 		Products: pbProducts,
+		Total:    total,
+	}, nil
+}
+
+func (s *grpcServer) SearchProducts(
+	ctx context.Context,
+	r *pb.SearchProductsRequest,
+) (*pb.SearchProductsResponse, error) {
+	q := SearchQuery{
+		Query:      r.Query,
+		Categories: r.Categories,
+		InStock:    r.InStock,
+		Sort:       SortFromProto(r.Sort),
+		Skip:       r.Skip,
+		Take:       r.Take,
+	}
+	if r.MinPrice != nil {
+		minPrice := money.FromProto(r.MinPrice)
+		q.MinPrice = &minPrice
+	}
+	if r.MaxPrice != nil {
+		maxPrice := money.FromProto(r.MaxPrice)
+		q.MaxPrice = &maxPrice
+	}
+	for _, a := range r.Attributes {
+		q.Attributes = append(q.Attributes, AttributeFilter{
+			Name:   a.Name,
+			Values: a.Values,
+		})
+	}
+
+	result, err := s.catalogService.Search(ctx, q)
+	if err != nil {
+		log.Println("Error searching products: ", err)
+		return nil, categoryError(strings.Join(r.Categories, ", "), err)
+	}
+
+	pbProducts := make([]*pb.Product, len(result.Products))
+	for index, p := range result.Products {
+		pbProducts[index] = productToProto(&p)
+	}
+
+	return &pb.SearchProductsResponse{
+		Products: pbProducts,
+		Total:    result.Total,
+		Facets:   facetsToProto(result.Facets),
+	}, nil
+}
+
+func (s *grpcServer) UpdateProduct(
+	ctx context.Context,
+	r *pb.UpdateProductRequest,
+) (*pb.UpdateProductResponse, error) {
+	product := Product{
+		ID:          r.Id,
+		Name:        r.Name,
+		Description: r.Description,
+		Price:       money.FromProto(r.Price),
+		Stock:       r.Stock,
+		TaxCategory: r.TaxCategory,
+		CategoryIDs: r.CategoryIds,
+		Options:     optionsFromProto(r.Options),
+		Variants:    variantsFromProto(r.Variants),
+	}
+	p, err := s.catalogService.UpdateProduct(ctx, product, r.UpdateMask.GetPaths())
+	if err != nil {
+		log.Println("Error updating product: ", err)
+		return nil, productError(r.Id, err)
+	}
+
+	return &pb.UpdateProductResponse{
+		Product: productToProto(p),
+	}, nil
+}
+
+func (s *grpcServer) DeleteProduct(
+	ctx context.Context,
+	r *pb.DeleteProductRequest,
+) (*pb.DeleteProductResponse, error) {
+	p, err := s.catalogService.DeleteProduct(ctx, r.Id)
+	if err != nil {
+		log.Println("Error deleting product: ", err)
+		return nil, productError(r.Id, err)
+	}
+
+	return &pb.DeleteProductResponse{
+		Product: productToProto(p),
+	}, nil
+}
+
+func (s *grpcServer) PostCategory(
+	ctx context.Context,
+	r *pb.PostCategoryRequest,
+) (*pb.PostCategoryResponse, error) {
+	c, err := s.catalogService.PostCategory(ctx, Category{
+		Slug:     r.Slug,
+		Name:     r.Name,
+		ParentID: r.ParentId,
+		Position: r.Position,
+	})
+	if err != nil {
+		log.Println("Error creating category: ", err)
+		return nil, err
+	}
+
+	return &pb.PostCategoryResponse{
+		Category: categoryToProto(c),
+	}, nil
+}
+
+func (s *grpcServer) GetCategories(
+	ctx context.Context,
+	r *pb.GetCategoriesRequest,
+) (*pb.GetCategoriesResponse, error) {
+	categories, err := s.catalogService.GetCategories(ctx)
+	if err != nil {
+		log.Println("Error getting categories: ", err)
+		return nil, err
+	}
+
+	pbCategories := make([]*pb.Category, len(categories))
+	for index, c := range categories {
+		pbCategories[index] = categoryToProto(&c)
+	}
+
+	return &pb.GetCategoriesResponse{
+		Categories: pbCategories,
+	}, nil
+}
+
+func (s *grpcServer) UpdateCategory(
+	ctx context.Context,
+	r *pb.UpdateCategoryRequest,
+) (*pb.UpdateCategoryResponse, error) {
+	category := Category{
+		ID:       r.Id,
+		Slug:     r.Slug,
+		Name:     r.Name,
+		ParentID: r.ParentId,
+		Position: r.Position,
+	}
+	c, err := s.catalogService.UpdateCategory(ctx, category, r.UpdateMask.GetPaths())
+	if err != nil {
+		log.Println("Error updating category: ", err)
+		return nil, categoryError(r.Id, err)
+	}
+
+	return &pb.UpdateCategoryResponse{
+		Category: categoryToProto(c),
+	}, nil
+}
+
+func (s *grpcServer) DeleteCategory(
+	ctx context.Context,
+	r *pb.DeleteCategoryRequest,
+) (*pb.DeleteCategoryResponse, error) {
+	if err := s.catalogService.DeleteCategory(ctx, r.Id); err != nil {
+		log.Println("Error deleting category: ", err)
+		return nil, categoryError(r.Id, err)
+	}
+
+	return &pb.DeleteCategoryResponse{}, nil
+}
+
+func (s *grpcServer) ReserveStock(
+	ctx context.Context,
+	r *pb.ReserveStockRequest,
+) (*pb.ReserveStockResponse, error) {
+	items := make([]StockItem, len(r.Items))
+	for index, item := range r.Items {
+		items[index] = StockItem{
+			ProductID: item.ProductId,
+			VariantID: item.VariantId,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	reservation, err := s.catalogService.ReserveStock(ctx, items)
+	if errors.Is(err, ErrNotFound) {
+		return nil, grpcerr.New(codes.NotFound, reasonProductNotFound, "product not found")
+	}
+	if err != nil {
+		log.Println("Error reserving stock: ", err)
+		return nil, stockError(err)
+	}
+
+	return &pb.ReserveStockResponse{
+		ReservationId: reservation.ID,
 	}, nil
 }
+
+func (s *grpcServer) ReleaseStock(
+	ctx context.Context,
+	r *pb.ReleaseStockRequest,
+) (*pb.ReleaseStockResponse, error) {
+	if err := s.catalogService.ReleaseStock(ctx, r.ReservationId); err != nil {
+		log.Println("Error releasing stock: ", err)
+		return nil, stockError(err)
+	}
+
+	return &pb.ReleaseStockResponse{}, nil
+}
+
+func (s *grpcServer) CommitStock(
+	ctx context.Context,
+	r *pb.CommitStockRequest,
+) (*pb.CommitStockResponse, error) {
+	if err := s.catalogService.CommitStock(ctx, r.ReservationId); err != nil {
+		log.Println("Error committing stock: ", err)
+		return nil, stockError(err)
+	}
+
+	return &pb.CommitStockResponse{}, nil
+}
+
+func (s *grpcServer) ConfirmReservation(
+	ctx context.Context,
+	r *pb.ConfirmReservationRequest,
+) (*pb.ConfirmReservationResponse, error) {
+	if err := s.catalogService.ConfirmReservation(ctx, r.ReservationId); err != nil {
+		log.Println("Error confirming reservation: ", err)
+		return nil, stockError(err)
+	}
+
+	return &pb.ConfirmReservationResponse{}, nil
+}
+
+func (s *grpcServer) GetExpiredReservations(
+	ctx context.Context,
+	r *pb.GetExpiredReservationsRequest,
+) (*pb.GetExpiredReservationsResponse, error) {
+	reservations, err := s.catalogService.GetExpiredReservations(ctx, r.Take)
+	if err != nil {
+		log.Println("Error getting expired reservations: ", err)
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, reservation := range reservations {
+		ids = append(ids, reservation.ID)
+	}
+	return &pb.GetExpiredReservationsResponse{ReservationIds: ids}, nil
+}
+
+func productError(id string, err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return grpcerr.New(codes.NotFound, reasonProductNotFound, fmt.Sprintf("product %s not found", id))
+	case errors.Is(err, ErrProductArchived):
+		return grpcerr.New(codes.FailedPrecondition, reasonProductArchived, fmt.Sprintf("product %s is archived", id))
+	}
+	return err
+}
+
+func categoryError(id string, err error) error {
+	switch {
+	case errors.Is(err, ErrCategoryNotFound):
+		return grpcerr.New(codes.NotFound, reasonCategoryNotFound, fmt.Sprintf("category %s not found", id))
+	case errors.Is(err, ErrCategoryHasChildren):
+		return grpcerr.New(codes.FailedPrecondition, reasonCategoryHasChildren, fmt.Sprintf("category %s has subcategories", id))
+	}
+	return err
+}
+
+func stockError(err error) error {
+	var stockErr *InsufficientStockError
+	switch {
+	case errors.As(err, &stockErr):
+		return grpcerr.NewWithMetadata(
+			codes.FailedPrecondition,
+			reasonInsufficientStock,
+			stockErr.Error(),
+			map[string]string{"product_id": stockErr.ProductID, "variant_id": stockErr.VariantID},
+		)
+	case errors.Is(err, ErrNotFound):
+		return grpcerr.New(codes.NotFound, reasonReservationNotFound, err.Error())
+	case errors.Is(err, ErrReservationSettled):
+		return grpcerr.New(codes.FailedPrecondition, reasonReservationSettled, err.Error())
+	}
+	return err
+}
+
+func productToProto(p *Product) *pb.Product {
+	pp := &pb.Product{
+		Id:            p.ID,
+		Name:          p.Name,
+		Description:   p.Description,
+		Price:         p.Price.Proto(),
+		Stock:         p.Stock,
+		ReservedStock: p.Reserved,
+		TaxCategory:   p.TaxCategory,
+		CategoryIds:   p.CategoryIDs,
+		Options:       optionsToProto(p.Options),
+		Variants:      variantsToProto(p.Variants),
+	}
+	if !p.CreatedAt.IsZero() {
+		pp.CreatedAt, _ = p.CreatedAt.MarshalBinary()
+	}
+	if p.ArchivedAt != nil {
+		pp.ArchivedAt, _ = p.ArchivedAt.MarshalBinary()
+	}
+	return pp
+}
+
+func optionsToProto(options []Option) []*pb.Option {
+	pbOptions := make([]*pb.Option, len(options))
+	for index, o := range options {
+		pbOptions[index] = &pb.Option{
+			Name:   o.Name,
+			Values: o.Values,
+		}
+	}
+	return pbOptions
+}
+
+func variantsToProto(variants []Variant) []*pb.Variant {
+	pbVariants := make([]*pb.Variant, len(variants))
+	for index, v := range variants {
+		values := make([]*pb.OptionValue, len(v.Options))
+		for i, o := range v.Options {
+			values[i] = &pb.OptionValue{
+				Name:  o.Name,
+				Value: o.Value,
+			}
+		}
+		pbVariants[index] = &pb.Variant{
+			Id:            v.ID,
+			Sku:           v.SKU,
+			Options:       values,
+			Price:         v.Price.Proto(),
+			Stock:         v.Stock,
+			ReservedStock: v.Reserved,
+		}
+	}
+	return pbVariants
+}
+
+func facetsToProto(f Facets) *pb.Facets {
+	pf := &pb.Facets{
+		Categories: facetValuesToProto(f.Categories),
+		InStock:    f.InStock,
+		OutOfStock: f.OutOfStock,
+	}
+	for _, a := range f.Attributes {
+		pf.Attributes = append(pf.Attributes, &pb.Facets_AttributeFacet{
+			Name:   a.Name,
+			Values: facetValuesToProto(a.Values),
+		})
+	}
+	for _, p := range f.Prices {
+		pf.Prices = append(pf.Prices, &pb.Facets_PriceRange{
+			Min: p.Min.Proto(),
+			Max: p.Max.Proto(),
+		})
+	}
+	return pf
+}
+
+func facetValuesToProto(values []FacetValue) []*pb.FacetValue {
+	pbValues := make([]*pb.FacetValue, len(values))
+	for index, v := range values {
+		pbValues[index] = &pb.FacetValue{
+			Value: v.Value,
+			Count: v.Count,
+		}
+	}
+	return pbValues
+}
+
+func categoryToProto(c *Category) *pb.Category {
+	return &pb.Category{
+		Id:       c.ID,
+		Slug:     c.Slug,
+		Name:     c.Name,
+		ParentId: c.ParentID,
+		Position: c.Position,
+	}
+}
@@ -2,17 +2,39 @@ package catalog
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/segmentio/ksuid"
+	"github.com/stiffinWanjohi/go-ecommerce/idempotency"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+	"github.com/stiffinWanjohi/go-ecommerce/tax"
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
 )
 
 type (
 	CatalogService interface {
 		PostProduct(ctx context.Context, p Product) (*Product, error)
 		GetProduct(ctx context.Context, id string) (*Product, error)
-		GetProducts(ctx context.Context, skip uint64, take uint64) ([]Product, error)
-		GetProductsByIDs(ctx context.Context, ids []string) ([]Product, error)
-		SearchProducts(ctx context.Context, query string, skip uint64, take uint64) ([]Product, error)
+		GetProducts(ctx context.Context, skip uint64, take uint64, category string, sort Sort) ([]Product, uint64, error)
+		GetProductsByIDs(ctx context.Context, ids []string, variantIDs []string) ([]Product, error)
+		UpdateProduct(ctx context.Context, p Product, fields []string) (*Product, error)
+		DeleteProduct(ctx context.Context, id string) (*Product, error)
+		SearchProducts(ctx context.Context, query string, skip uint64, take uint64, category string, sort Sort) ([]Product, uint64, error)
+		Search(ctx context.Context, q SearchQuery) (*SearchResult, error)
+		PostCategory(ctx context.Context, c Category) (*Category, error)
+		GetCategories(ctx context.Context) ([]Category, error)
+		UpdateCategory(ctx context.Context, c Category, fields []string) (*Category, error)
+		DeleteCategory(ctx context.Context, id string) error
+		ReserveStock(ctx context.Context, items []StockItem) (*Reservation, error)
+		ReleaseStock(ctx context.Context, reservationID string) error
+		CommitStock(ctx context.Context, reservationID string) error
+		ConfirmReservation(ctx context.Context, reservationID string) error
+		GetExpiredReservations(ctx context.Context, take uint64) ([]Reservation, error)
 	}
 
 	catalogService struct {
@@ -20,13 +42,104 @@ type (
 	}
 
 	Product struct {
-		ID          string  `json:"id"`
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		Price       float64 `json:"price"`
+		ID          string      `json:"id"`
+		Name        string      `json:"name"`
+		Description string      `json:"description"`
+		Price       money.Money `json:"price"`
+		Stock       uint32      `json:"stock"`
+		Reserved    uint32      `json:"reserved"`
+		TaxCategory string      `json:"tax_category"`
+		CategoryIDs []string    `json:"category_ids,omitempty"`
+
+		// Options and Variants are empty for products sold as they are.
+		// Products with variants are ordered and stocked by variant, Price
+		// and Stock of the product itself are then not used for orders.
+		Options  []Option  `json:"options,omitempty"`
+		Variants []Variant `json:"variants,omitempty"`
+
+		// CreatedAt is zero for products created before it was recorded
+		CreatedAt time.Time `json:"created_at"`
+
+		// ArchivedAt is set once the product is deleted. Archived products
+		// are kept for the orders that reference them.
+		ArchivedAt *time.Time `json:"archived_at,omitempty"`
+
+		// IdempotencyKey is the optional client supplied key the product
+		// was created with. It is not part of the stored product.
+		IdempotencyKey string `json:"-"`
 	}
 )
 
+var ErrProductArchived = errors.New("product is archived")
+
+// The fields of products and categories that can be updated, named as in
+// the API.
+const (
+	FieldName        = "name"
+	FieldDescription = "description"
+	FieldPrice       = "price"
+	FieldStock       = "stock"
+	FieldTaxCategory = "taxCategory"
+	FieldCategoryIDs = "categoryIds"
+	FieldVariants    = "variants"
+	FieldSlug        = "slug"
+	FieldParentID    = "parentId"
+	FieldPosition    = "position"
+)
+
+var (
+	updatableFields         = []string{FieldName, FieldDescription, FieldPrice, FieldStock, FieldTaxCategory, FieldCategoryIDs, FieldVariants}
+	updatableCategoryFields = []string{FieldName, FieldSlug, FieldParentID, FieldPosition}
+)
+
+const (
+	MaxNameLength        = 255
+	MaxDescriptionLength = 4096
+	MaxTaxCategoryLength = 64
+)
+
+func (p Product) Validate() error {
+	v := &validation.Error{}
+	v.Check(validation.Length(p.Name, 1, MaxNameLength), "name", "is required and must be at most %d characters", MaxNameLength)
+	v.Check(validation.Length(p.Description, 0, MaxDescriptionLength), "description", "must be at most %d characters", MaxDescriptionLength)
+	v.Check(!p.Price.IsNegative(), "price.amount", "must not be negative")
+	v.Check(len(p.Price.Currency) == 3, "price.currency", "must be a 3-letter ISO 4217 code")
+	v.Check(validation.Length(p.TaxCategory, 0, MaxTaxCategoryLength), "taxCategory", "must be at most %d characters", MaxTaxCategoryLength)
+	p.validateVariants(v)
+	return v.Err()
+}
+
+func (p Product) Fingerprint() string {
+	fields := []string{
+		p.Name,
+		p.Description,
+		p.Price.String(),
+		fmt.Sprint(p.Stock),
+		p.TaxCategory,
+	}
+	// Left out when empty to keep the fingerprints of older keys
+	if len(p.CategoryIDs) > 0 {
+		ids := append([]string{}, p.CategoryIDs...)
+		sort.Strings(ids)
+		fields = append(fields, "categories:"+strings.Join(ids, ","))
+	}
+	for _, o := range p.Options {
+		fields = append(fields, fmt.Sprintf("option:%q:%q", o.Name, o.Values))
+	}
+	for _, v := range p.Variants {
+		fields = append(fields, fmt.Sprintf("variant:%q:%q:%s:%d", v.SKU, v.Options, v.Price, v.Stock))
+	}
+	return idempotency.Fingerprint(fields...)
+}
+
+// Available is the quantity that can still be ordered.
+func (p Product) Available() uint32 {
+	if p.Reserved > p.Stock {
+		return 0
+	}
+	return p.Stock - p.Reserved
+}
+
 func NewCatalogService(r CatalogRepository) CatalogService {
 	return &catalogService{r}
 }
@@ -36,20 +149,120 @@ func (s *catalogService) PostProduct(
 	p Product,
 ) (*Product, error) {
 	product := Product{
-		ID:          ksuid.New().String(),
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
+		ID:             ksuid.New().String(),
+		Name:           p.Name,
+		Description:    p.Description,
+		Price:          money.New(p.Price.Amount, p.Price.Currency),
+		Stock:          p.Stock,
+		TaxCategory:    p.TaxCategory,
+		CategoryIDs:    uniqueStrings(p.CategoryIDs),
+		Options:        p.Options,
+		CreatedAt:      time.Now().UTC(),
+		IdempotencyKey: p.IdempotencyKey,
+	}
+	if product.TaxCategory == "" {
+		product.TaxCategory = tax.DefaultCategory
+	}
+	for _, variant := range p.Variants {
+		// New products cannot refer to existing variants
+		variant.ID = ""
+		product.Variants = append(product.Variants, variant)
+	}
+	variants, err := mergeVariants(product.Variants, nil, product.Price.Currency)
+	if err != nil {
+		return nil, err
+	}
+	product.Variants = variants
+	if err := s.validateProduct(ctx, product); err != nil {
+		return nil, err
 	}
 
-	err := s.repository.PutProduct(ctx, product)
+	if product.IdempotencyKey != "" {
+		// Replay the original product if this request was already handled
+		replayed, err := s.getIdempotentProduct(ctx, product)
+		if err != nil || replayed != nil {
+			return replayed, err
+		}
+
+		err = s.repository.ClaimIdempotencyKey(ctx, product.IdempotencyKey, product.Fingerprint(), product.ID)
+		if errors.Is(err, ErrIdempotencyKeyClaimed) {
+			return s.getIdempotentProduct(ctx, product)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.repository.PutProduct(ctx, product)
 	if err != nil {
+		if product.IdempotencyKey != "" {
+			if err := s.repository.DeleteIdempotencyKey(ctx, product.IdempotencyKey); err != nil {
+				log.Println("Error deleting idempotency key: ", err)
+			}
+		}
 		return nil, err
 	}
 
 	return &product, nil
 }
 
+// validateProduct checks p and that its categories exist.
+func (s *catalogService) validateProduct(
+	ctx context.Context,
+	p Product,
+) error {
+	v := &validation.Error{}
+	v.Merge("", p.Validate())
+	if len(p.CategoryIDs) > 0 {
+		categories, err := s.repository.ListCategories(ctx)
+		if err != nil {
+			return err
+		}
+		tree := newCategoryTree(categories)
+		for i, id := range p.CategoryIDs {
+			_, ok := tree.byID[id]
+			v.Check(ok, fmt.Sprintf("categoryIds[%d]", i), "category %s does not exist", id)
+		}
+	}
+	return v.Err()
+}
+
+func uniqueStrings(values []string) []string {
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+func (s *catalogService) getIdempotentProduct(
+	ctx context.Context,
+	p Product,
+) (*Product, error) {
+	productID, fingerprint, err := s.repository.GetIdempotencyKey(ctx, p.IdempotencyKey)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if fingerprint != p.Fingerprint() {
+		return nil, idempotency.ErrConflict
+	}
+
+	// The key is claimed before the product is written
+	product, err := s.repository.GetProductByID(ctx, productID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, idempotency.ErrInProgress
+	}
+	return product, err
+}
+
 func (s *catalogService) GetProduct(
 	ctx context.Context,
 	id string,
@@ -61,22 +274,160 @@ func (s *catalogService) GetProducts(
 	ctx context.Context,
 	skip uint64,
 	take uint64,
-) ([]Product, error) {
+	category string,
+	sort Sort,
+) ([]Product, uint64, error) {
+	if err := sort.Validate(); err != nil {
+		return nil, 0, err
+	}
 	if take > 100 || (skip == 0 && take == 0) {
 		take = 100
 	}
-	return s.repository.ListProducts(ctx, skip, take)
+	categoryIDs, err := s.categorySubtrees(ctx, category)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repository.ListProducts(ctx, skip, take, categoryIDs, sort)
+}
+
+// categorySubtrees returns the IDs of the categories with the given IDs or
+// slugs and of all categories below them, or nil for no category. Empty
+// IDs are ignored.
+func (s *catalogService) categorySubtrees(
+	ctx context.Context,
+	categories ...string,
+) ([]string, error) {
+	wanted := []string{}
+	for _, c := range categories {
+		if c != "" {
+			wanted = append(wanted, c)
+		}
+	}
+	if len(wanted) == 0 {
+		return nil, nil
+	}
+
+	all, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree := newCategoryTree(all)
+	ids := []string{}
+	for _, category := range wanted {
+		c, ok := tree.find(category)
+		if !ok {
+			return nil, ErrCategoryNotFound
+		}
+		ids = append(ids, tree.subtree(c.ID)...)
+	}
+	return uniqueStrings(ids), nil
 }
 
 func (s *catalogService) GetProductsByIDs(
 	ctx context.Context,
 	ids []string,
+	variantIDs []string,
 ) ([]Product, error) {
-	if len(ids) == 0 {
+	if len(ids) == 0 && len(variantIDs) == 0 {
 		return []Product{}, nil
 	}
 
-	return s.repository.ListProductsWithIDs(ctx, ids)
+	return s.repository.ListProductsWithIDs(ctx, ids, variantIDs)
+}
+
+// UpdateProduct changes the given fields of the product to those of p,
+// or all of them when fields is empty. Reserved stock is never changed.
+func (s *catalogService) UpdateProduct(
+	ctx context.Context,
+	p Product,
+	fields []string,
+) (*Product, error) {
+	if len(fields) == 0 {
+		fields = updatableFields
+	}
+	if err := checkFields(fields, updatableFields); err != nil {
+		return nil, err
+	}
+
+	product, err := s.repository.GetProductByID(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if product.ArchivedAt != nil {
+		return nil, ErrProductArchived
+	}
+
+	for _, field := range fields {
+		switch field {
+		case FieldName:
+			product.Name = p.Name
+		case FieldDescription:
+			product.Description = p.Description
+		case FieldPrice:
+			product.Price = money.New(p.Price.Amount, p.Price.Currency)
+		case FieldStock:
+			product.Stock = p.Stock
+		case FieldTaxCategory:
+			product.TaxCategory = p.TaxCategory
+			if product.TaxCategory == "" {
+				product.TaxCategory = tax.DefaultCategory
+			}
+		case FieldCategoryIDs:
+			product.CategoryIDs = uniqueStrings(p.CategoryIDs)
+		case FieldVariants:
+			// Merged below, in the currency of the updated price
+			product.Options = p.Options
+		}
+	}
+	if contains(fields, FieldVariants) {
+		product.Variants, err = mergeVariants(p.Variants, product.Variants, product.Price.Currency)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.validateProduct(ctx, *product); err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.UpdateProduct(ctx, *product, fields); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// checkFields reports the fields of an update mask that are not updatable.
+func checkFields(fields []string, updatable []string) error {
+	v := &validation.Error{}
+	for _, field := range fields {
+		ok := false
+		for _, f := range updatable {
+			ok = ok || f == field
+		}
+		v.Check(ok, "updateMask", "cannot update %s", field)
+	}
+	return v.Err()
+}
+
+// DeleteProduct archives the product. Archiving an archived product
+// changes nothing.
+func (s *catalogService) DeleteProduct(
+	ctx context.Context,
+	id string,
+) (*Product, error) {
+	product, err := s.repository.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product.ArchivedAt != nil {
+		return product, nil
+	}
+
+	archivedAt := time.Now()
+	if err := s.repository.ArchiveProduct(ctx, id, archivedAt); err != nil {
+		return nil, err
+	}
+	product.ArchivedAt = &archivedAt
+	return product, nil
 }
 
 func (s *catalogService) SearchProducts(
@@ -84,9 +435,298 @@ func (s *catalogService) SearchProducts(
 	query string,
 	skip uint64,
 	take uint64,
-) ([]Product, error) {
+	category string,
+	sort Sort,
+) ([]Product, uint64, error) {
+	if err := sort.Validate(); err != nil {
+		return nil, 0, err
+	}
 	if take > 100 || (skip == 0 && take == 0) {
 		take = 100
 	}
-	return s.repository.SearchProducts(ctx, query, skip, take)
+	categoryIDs, err := s.categorySubtrees(ctx, category)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repository.SearchProducts(ctx, query, skip, take, categoryIDs, sort)
+}
+
+// Search finds the products matching q, with the facets of all of them
+// and not only of the page returned.
+func (s *catalogService) Search(
+	ctx context.Context,
+	q SearchQuery,
+) (*SearchResult, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	if q.Take > 100 || (q.Skip == 0 && q.Take == 0) {
+		q.Take = 100
+	}
+	categoryIDs, err := s.categorySubtrees(ctx, q.Categories...)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.Search(ctx, q, categoryIDs)
+}
+
+// PostCategory adds c to the category tree, under its parent if it has
+// one. Categories are managed by admins only, so the unique slug check
+// does not guard against concurrent changes.
+func (s *catalogService) PostCategory(
+	ctx context.Context,
+	c Category,
+) (*Category, error) {
+	category := Category{
+		ID:       ksuid.New().String(),
+		Slug:     strings.ToLower(strings.TrimSpace(c.Slug)),
+		Name:     strings.TrimSpace(c.Name),
+		ParentID: c.ParentID,
+		Position: c.Position,
+	}
+
+	categories, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree := newCategoryTree(categories)
+
+	v := &validation.Error{}
+	v.Check(len(categories) < MaxCategories, "category", "cannot be added, the tree is limited to %d categories", MaxCategories)
+	v.Merge("", tree.check(category))
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.PutCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetCategories returns the whole category tree as a list, siblings in
+// their order.
+func (s *catalogService) GetCategories(ctx context.Context) ([]Category, error) {
+	categories, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sortCategories(categories)
+	return categories, nil
+}
+
+// UpdateCategory changes the given fields of the category to those of c,
+// or all of them when fields is empty. Changing the parent moves the
+// category with all of its descendants.
+func (s *catalogService) UpdateCategory(
+	ctx context.Context,
+	c Category,
+	fields []string,
+) (*Category, error) {
+	if len(fields) == 0 {
+		fields = updatableCategoryFields
+	}
+	if err := checkFields(fields, updatableCategoryFields); err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree := newCategoryTree(categories)
+	category, ok := tree.byID[c.ID]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+
+	for _, field := range fields {
+		switch field {
+		case FieldName:
+			category.Name = strings.TrimSpace(c.Name)
+		case FieldSlug:
+			category.Slug = strings.ToLower(strings.TrimSpace(c.Slug))
+		case FieldParentID:
+			category.ParentID = c.ParentID
+		case FieldPosition:
+			category.Position = c.Position
+		}
+	}
+	if err := tree.check(category); err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.PutCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// DeleteCategory removes a category without children and takes it off
+// its products.
+func (s *catalogService) DeleteCategory(
+	ctx context.Context,
+	id string,
+) error {
+	categories, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return err
+	}
+	tree := newCategoryTree(categories)
+	if _, ok := tree.byID[id]; !ok {
+		return ErrCategoryNotFound
+	}
+	if len(tree.children[id]) > 0 {
+		return ErrCategoryHasChildren
+	}
+
+	// Products first, so a failure leaves a category that can be deleted
+	// again rather than products in a missing category
+	if err := s.repository.RemoveCategoryFromProducts(ctx, id); err != nil {
+		return err
+	}
+	err = s.repository.DeleteCategory(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return ErrCategoryNotFound
+	}
+	return err
+}
+
+func (s *catalogService) ReserveStock(
+	ctx context.Context,
+	items []StockItem,
+) (*Reservation, error) {
+	// Merge lines for the same product or variant so each is reserved in
+	// one step
+	positions := map[StockItem]int{}
+	merged := []StockItem{}
+	for _, item := range items {
+		key := StockItem{ProductID: item.ProductID, VariantID: item.VariantID}
+		position, ok := positions[key]
+		if !ok {
+			position = len(merged)
+			positions[key] = position
+			merged = append(merged, key)
+		}
+		merged[position].Quantity += item.Quantity
+	}
+
+	// Store the reservation before holding any stock, so that a crash part
+	// way leaves an expiring reservation to sweep
+	createdAt := time.Now()
+	expiresAt := createdAt.Add(ReservationTTL)
+	reservation := Reservation{
+		ID:        ksuid.New().String(),
+		Items:     merged,
+		Status:    ReservationReserved,
+		CreatedAt: createdAt,
+		ExpiresAt: &expiresAt,
+		Holds:     true,
+	}
+
+	if err := s.repository.PutReservation(ctx, reservation); err != nil {
+		return nil, err
+	}
+
+	// Reserve all or nothing
+	reserved := []StockItem{}
+	for _, item := range merged {
+		if err := s.repository.ReserveStock(ctx, reservation.ID, item); err != nil {
+			s.releaseItems(ctx, reservation.ID, reserved)
+			if err := s.repository.SettleReservation(ctx, reservation.ID, ReservationReleased); err != nil {
+				log.Printf("Error releasing reservation %s: %v", reservation.ID, err)
+			}
+			return nil, err
+		}
+		reserved = append(reserved, item)
+	}
+
+	return &reservation, nil
+}
+
+func (s *catalogService) ReleaseStock(
+	ctx context.Context,
+	reservationID string,
+) error {
+	reservation, err := s.settleReservation(ctx, reservationID, ReservationReleased)
+	if err != nil || reservation == nil {
+		return err
+	}
+
+	s.releaseItems(ctx, reservation.holdID(), reservation.Items)
+	return nil
+}
+
+func (s *catalogService) CommitStock(
+	ctx context.Context,
+	reservationID string,
+) error {
+	reservation, err := s.settleReservation(ctx, reservationID, ReservationCommitted)
+	if err != nil || reservation == nil {
+		return err
+	}
+
+	for _, item := range reservation.Items {
+		if err := s.repository.CommitStock(ctx, reservation.holdID(), item); err != nil {
+			log.Printf("Error committing stock of product %s: %v", item.ProductID, err)
+		}
+	}
+	return nil
+}
+
+// settleReservation moves a reservation out of the reserved state. It
+// returns a nil reservation if it was already in the requested state, so
+// that releasing or committing twice is harmless.
+func (s *catalogService) settleReservation(
+	ctx context.Context,
+	id string,
+	status ReservationStatus,
+) (*Reservation, error) {
+	reservation, err := s.repository.GetReservation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if reservation.Status == status {
+		return nil, nil
+	}
+
+	if err := s.repository.SettleReservation(ctx, id, status); err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// ConfirmReservation keeps the reservation from expiring, once the order
+// it was taken for is placed.
+func (s *catalogService) ConfirmReservation(
+	ctx context.Context,
+	reservationID string,
+) error {
+	return s.repository.ConfirmReservation(ctx, reservationID)
+}
+
+// GetExpiredReservations returns up to take reservations, oldest first,
+// that still hold stock past their expiry.
+func (s *catalogService) GetExpiredReservations(
+	ctx context.Context,
+	take uint64,
+) ([]Reservation, error) {
+	if take == 0 || take > 100 {
+		take = 100
+	}
+	return s.repository.ListExpiredReservations(ctx, time.Now(), take)
+}
+
+func (s *catalogService) releaseItems(
+	ctx context.Context,
+	holdID string,
+	items []StockItem,
+) {
+	for _, item := range items {
+		if err := s.repository.ReleaseStock(ctx, holdID, item); err != nil {
+			log.Printf("Error releasing stock of product %s: %v", item.ProductID, err)
+		}
+	}
 }
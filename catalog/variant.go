@@ -0,0 +1,175 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
+)
+
+const (
+	MaxOptions           = 3
+	MaxOptionValues      = 100
+	MaxVariants          = 100
+	MaxOptionNameLength  = 32
+	MaxOptionValueLength = 64
+	MaxSKULength         = 64
+)
+
+type (
+	// Option is a way a product comes in, such as "Size" with the values
+	// "S", "M" and "L".
+	Option struct {
+		Name   string   `json:"name"`
+		Values []string `json:"values"`
+	}
+
+	OptionValue struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	// Variant is a version of a product that is sold on its own, with one
+	// value for each of the product's options and its own SKU, price and
+	// stock.
+	Variant struct {
+		ID       string        `json:"id"`
+		SKU      string        `json:"sku"`
+		Options  []OptionValue `json:"options"`
+		Price    money.Money   `json:"price"`
+		Stock    uint32        `json:"stock"`
+		Reserved uint32        `json:"reserved"`
+	}
+)
+
+// Available is the quantity that can still be ordered.
+func (v Variant) Available() uint32 {
+	if v.Reserved > v.Stock {
+		return 0
+	}
+	return v.Stock - v.Reserved
+}
+
+// Title joins the option values, such as "M / Blue".
+func (v Variant) Title() string {
+	values := []string{}
+	for _, o := range v.Options {
+		values = append(values, o.Value)
+	}
+	return strings.Join(values, " / ")
+}
+
+// Variant returns the variant of p with the given ID.
+func (p Product) Variant(id string) (Variant, bool) {
+	for _, v := range p.Variants {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// validateVariants checks the options of p and that every variant picks
+// one value of each of them, in their order, with no two variants alike.
+func (p Product) validateVariants(v *validation.Error) {
+	v.Check(len(p.Options) <= MaxOptions, "options", "must be at most %d", MaxOptions)
+	v.Check(len(p.Variants) <= MaxVariants, "variants", "must be at most %d", MaxVariants)
+	v.Check(len(p.Options) == 0 || len(p.Variants) > 0, "variants", "are required for a product with options")
+	v.Check(len(p.Options) > 0 || len(p.Variants) == 0, "options", "are required for a product with variants")
+
+	names := map[string]bool{}
+	for i, o := range p.Options {
+		field := fmt.Sprintf("options[%d]", i)
+		v.Check(validation.Length(o.Name, 1, MaxOptionNameLength), field+".name", "is required and must be at most %d characters", MaxOptionNameLength)
+		v.Check(!names[o.Name], field+".name", "duplicates option %s", o.Name)
+		names[o.Name] = true
+
+		v.Check(len(o.Values) > 0 && len(o.Values) <= MaxOptionValues, field+".values", "must have 1 to %d values", MaxOptionValues)
+		values := map[string]bool{}
+		for j, value := range o.Values {
+			v.Check(validation.Length(value, 1, MaxOptionValueLength), fmt.Sprintf("%s.values[%d]", field, j), "is required and must be at most %d characters", MaxOptionValueLength)
+			v.Check(!values[value], fmt.Sprintf("%s.values[%d]", field, j), "duplicates value %s", value)
+			values[value] = true
+		}
+	}
+
+	skus := map[string]bool{}
+	combinations := map[string]bool{}
+	for i, variant := range p.Variants {
+		field := fmt.Sprintf("variants[%d]", i)
+		v.Check(validation.Length(variant.SKU, 1, MaxSKULength), field+".sku", "is required and must be at most %d characters", MaxSKULength)
+		v.Check(!skus[variant.SKU], field+".sku", "duplicates SKU %s", variant.SKU)
+		skus[variant.SKU] = true
+
+		v.Check(!variant.Price.IsNegative(), field+".price.amount", "must not be negative")
+		v.Check(variant.Price.Currency == p.Price.Currency, field+".price.currency", "must be the product's currency %s", p.Price.Currency)
+
+		v.Check(len(variant.Options) == len(p.Options), field+".options", "must have a value for each of the %d options", len(p.Options))
+		for j, o := range variant.Options {
+			if j >= len(p.Options) {
+				break
+			}
+			option := p.Options[j]
+			v.Check(o.Name == option.Name, fmt.Sprintf("%s.options[%d].name", field, j), "must be %s", option.Name)
+			v.Check(contains(option.Values, o.Value), fmt.Sprintf("%s.options[%d].value", field, j), "must be one of the values of %s", option.Name)
+		}
+		key := fmt.Sprintf("%q", variant.Options)
+		v.Check(!combinations[key], field+".options", "duplicate the options of another variant")
+		combinations[key] = true
+	}
+}
+
+// mergeVariants gives the requested variants without an ID a new one and
+// those with an ID the stock reserved for them. Prices without a currency,
+// see money.ParseAmount, are in the product's currency. Variants with
+// reserved stock cannot be removed.
+func mergeVariants(requested []Variant, existing []Variant, currency string) ([]Variant, error) {
+	known := map[string]Variant{}
+	for _, e := range existing {
+		known[e.ID] = e
+	}
+
+	v := &validation.Error{}
+	variants := []Variant{}
+	kept := map[string]bool{}
+	for i, r := range requested {
+		price := money.New(r.Price.Amount, r.Price.Currency)
+		if r.Price.Currency == "" {
+			var err error
+			price, err = r.Price.In(currency)
+			v.Check(err == nil, fmt.Sprintf("variants[%d].price.amount", i), "must be a decimal amount in the minor unit precision of %s", currency)
+		}
+		variant := Variant{
+			ID:      r.ID,
+			SKU:     strings.TrimSpace(r.SKU),
+			Options: r.Options,
+			Price:   price,
+			Stock:   r.Stock,
+		}
+		if variant.ID == "" {
+			variant.ID = ksuid.New().String()
+		} else {
+			e, ok := known[variant.ID]
+			v.Check(ok, fmt.Sprintf("variants[%d].id", i), "variant %s does not exist", variant.ID)
+			v.Check(!kept[variant.ID], fmt.Sprintf("variants[%d].id", i), "duplicates variant %s", variant.ID)
+			variant.Reserved = e.Reserved
+			kept[variant.ID] = true
+		}
+		variants = append(variants, variant)
+	}
+	for _, e := range existing {
+		v.Check(kept[e.ID] || e.Reserved == 0, "variants", "cannot remove variant %s with %d units reserved", e.ID, e.Reserved)
+	}
+	return variants, v.Err()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -25,23 +25,29 @@ func (r *accountResolver) Orders(
 
 	var orders []*Order
 	for _, o := range orderList {
-		var products []*OrderedProduct
-		for _, p := range o.Products {
-			products = append(products, &OrderedProduct{
-				ID:          p.ID,
-				Name:        p.Name,
-				Description: p.Description,
-				Price:       p.Price,
-				Quantity:    int(p.Quantity),
-			})
-		}
-		orders = append(orders, &Order{
-			ID:         o.ID,
-			CreatedAt:  o.CreatedAt,
-			TotalPrice: o.TotalPrice,
-			Products:   products,
-		})
+		orders = append(orders, newOrder(o))
 	}
 
 	return orders, nil
 }
+
+func (r *accountResolver) Addresses(
+	ctx context.Context,
+	obj *Account,
+) ([]*AccountAddress, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	addressList, err := r.server.accountClient.GetAddresses(ctx, obj.ID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	addresses := []*AccountAddress{}
+	for _, a := range addressList {
+		addresses = append(addresses, newAccountAddress(a))
+	}
+
+	return addresses, nil
+}
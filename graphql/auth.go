@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
+)
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrForbidden       = errors.New("access denied")
+)
+
+// owned is implemented by inputs and objects that belong to an account.
+type owned interface {
+	ownerID() string
+}
+
+func (in OrderInput) ownerID() string    { return in.AccountID }
+func (in CartItemInput) ownerID() string { return in.AccountID }
+func (a *Account) ownerID() string       { return a.ID }
+
+// authenticate puts the principal of a valid bearer token into the request
+// context. Requests without a token continue anonymously, requests with an
+// invalid one are rejected.
+func authenticate(signer *auth.Signer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			http.Error(w, "unsupported authorization scheme", http.StatusUnauthorized)
+			return
+		}
+
+		p, err := signer.Authenticate(strings.TrimSpace(token))
+		if err != nil {
+			http.Error(w, auth.ErrInvalidToken.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		ctx := auth.NewContext(r.Context(), p)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func principal(ctx context.Context) (auth.Principal, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.Principal{}, ErrUnauthenticated
+	}
+	return p, nil
+}
+
+// authorizeOwner fails unless the caller may act on the account.
+func authorizeOwner(ctx context.Context, accountID string) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if !p.CanAccess(accountID) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func hasRole(
+	ctx context.Context,
+	obj interface{},
+	next graphql.Resolver,
+	role Role,
+) (interface{}, error) {
+	p, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !p.HasRole(strings.ToLower(role.String())) {
+		return nil, ErrForbidden
+	}
+	return next(ctx)
+}
+
+// isOwner lets the owner of the account and admins through. The account is
+// taken from the argument named arg, or from the parent object when arg is
+// not given. A missing owner leaves the field to admins.
+func isOwner(
+	ctx context.Context,
+	obj interface{},
+	next graphql.Resolver,
+	arg *string,
+) (interface{}, error) {
+	var accountID string
+	if arg != nil {
+		accountID = ownerOf(graphql.GetFieldContext(ctx).Args[*arg])
+	} else {
+		accountID = ownerOf(obj)
+	}
+
+	if err := authorizeOwner(ctx, accountID); err != nil {
+		return nil, err
+	}
+	return next(ctx)
+}
+
+func ownerOf(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case *string:
+		return value(v)
+	case owned:
+		return v.ownerID()
+	}
+	return ""
+}
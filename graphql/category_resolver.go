@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type categoryResolver struct {
+	server *Server
+}
+
+func (r *categoryResolver) Products(
+	ctx context.Context,
+	obj *Category,
+	pagination *PaginationInput,
+	sort *ProductSort,
+) (*ProductList, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	skip, take := uint64(0), uint64(0)
+	if pagination != nil {
+		skip, take = pagination.bounds()
+	}
+
+	productList, total, err := r.server.catalogClient.GetProducts(ctx, skip, take, nil, "", obj.ID, sort.sort())
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newProductList(productList, total), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stiffinWanjohi/go-ecommerce/grpcerr"
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/grpc/codes"
+)
+
+// presentError adds the kind of failure as the `code` extension of every
+// error, such as NOT_FOUND, and the service's more specific `reason`, such
+// as ACCOUNT_NOT_FOUND, when it gave one.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]interface{}{}
+	}
+	if _, ok := gqlErr.Extensions["code"]; ok {
+		return gqlErr
+	}
+
+	var statusErr *grpcerr.Error
+	if errors.As(err, &statusErr) {
+		code := grpcerr.CodeName(statusErr.Code())
+		gqlErr.Extensions["code"] = code
+		if reason := statusErr.Reason(); reason != code {
+			gqlErr.Extensions["reason"] = reason
+		}
+		if len(statusErr.Metadata()) > 0 {
+			gqlErr.Extensions["metadata"] = statusErr.Metadata()
+		}
+		return gqlErr
+	}
+
+	gqlErr.Extensions["code"] = grpcerr.CodeName(errorCode(err))
+	return gqlErr
+}
+
+// splitViolations reports every field violation of a failed field as an
+// error of its own, with the offending field as the `field` extension.
+func splitViolations(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	res, err := next(ctx)
+
+	var v *validation.Error
+	if !errors.As(err, &v) || len(v.Violations) == 0 {
+		return res, err
+	}
+
+	errs := []*gqlerror.Error{}
+	for _, fv := range v.Violations {
+		errs = append(errs, &gqlerror.Error{
+			Message: fv.Field + " " + fv.Description,
+			Extensions: map[string]interface{}{
+				"code":   grpcerr.CodeName(codes.InvalidArgument),
+				"reason": grpcerr.ReasonValidationFailed,
+				"field":  fv.Field,
+			},
+		})
+	}
+	for _, e := range errs[:len(errs)-1] {
+		graphql.AddError(ctx, e)
+	}
+	return res, errs[len(errs)-1]
+}
+
+// errorCode classifies the errors raised by the gateway itself.
+func errorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return codes.Unauthenticated
+	case errors.Is(err, ErrForbidden):
+		return codes.PermissionDenied
+	case errors.As(err, new(*validation.Error)):
+		return codes.InvalidArgument
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/stiffinWanjohi/go-ecommerce/account"
+	"github.com/stiffinWanjohi/go-ecommerce/cart"
 	"github.com/stiffinWanjohi/go-ecommerce/catalog"
 	"github.com/stiffinWanjohi/go-ecommerce/order"
 )
@@ -11,34 +12,45 @@ type Server struct {
 	accountClient *account.Client
 	catalogClient *catalog.Client
 	orderClient   *order.Client
+	cartClient    *cart.Client
 }
 
-func NewGraphQLServer(accountUrl, catalogUrl, orderUrl string) (*Server, error) {
+func NewGraphQLServer(accountUrl, catalogUrl, orderUrl, cartUrl string) (*Server, error) {
 	// connect to account service
-	accountClient, err := account.NewClient(accountUrl)
+	accountClient, err := account.NewClient(accountUrl, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// connect to product service
-	catalogClient, err := catalog.NewClient(catalogUrl)
+	catalogClient, err := catalog.NewClient(catalogUrl, nil)
 	if err != nil {
 		accountClient.Close()
 		return nil, err
 	}
 
 	// connect to order service
-	orderClient, err := order.NewClient(orderUrl)
+	orderClient, err := order.NewClient(orderUrl, nil)
 	if err != nil {
 		accountClient.Close()
 		catalogClient.Close()
 		return nil, err
 	}
 
+	// connect to cart service
+	cartClient, err := cart.NewClient(cartUrl, nil)
+	if err != nil {
+		accountClient.Close()
+		catalogClient.Close()
+		orderClient.Close()
+		return nil, err
+	}
+
 	return &Server{
 		accountClient,
 		catalogClient,
 		orderClient,
+		cartClient,
 	}, nil
 }
 
@@ -60,8 +72,18 @@ func (s *Server) Account() AccountResolver {
 	}
 }
 
+func (s *Server) Category() CategoryResolver {
+	return &categoryResolver{
+		server: s,
+	}
+}
+
 func (s *Server) ToExecutableSchema() graphql.ExecutableSchema {
 	return NewExecutableSchema(Config{
 		Resolvers: s,
+		Directives: DirectiveRoot{
+			HasRole: hasRole,
+			IsOwner: isOwner,
+		},
 	})
 }
@@ -7,12 +7,18 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/kelseyhightower/envconfig"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
 )
 
 type AppConfig struct {
 	AccountURL string `envconfig:"ACCOUNT_SERVICE_URL" required:"true"`
 	CatalogURL string `envconfig:"CATALOG_SERVICE_URL" required:"true"`
 	OrderURL   string `envconfig:"ORDER_SERVICE_URL" required:"true"`
+	CartURL    string `envconfig:"CART_SERVICE_URL" required:"true"`
+
+	// SessionSecret verifies the session tokens issued by the account
+	// service.
+	SessionSecret string `envconfig:"SESSION_SECRET" required:"true"`
 }
 
 func main() {
@@ -22,12 +28,16 @@ func main() {
 		log.Fatal(err)
 	}
 
-	s, err := NewGraphQLServer(cfg.AccountURL, cfg.CatalogURL, cfg.OrderURL)
+	s, err := NewGraphQLServer(cfg.AccountURL, cfg.CatalogURL, cfg.OrderURL, cfg.CartURL)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	http.Handle("/graphql", handler.NewDefaultServer(s.ToExecutableSchema()))
+	signer := auth.NewSigner(cfg.SessionSecret, 0)
+	srv := handler.NewDefaultServer(s.ToExecutableSchema())
+	srv.SetErrorPresenter(presentError)
+	srv.AroundFields(splitViolations)
+	http.Handle("/graphql", authenticate(signer, srv))
 	http.Handle("/playground", playground.Handler("go-ecommerce", "/graphql"))
 
 	log.Fatal(http.ListenAndServe(":8001", nil))
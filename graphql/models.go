@@ -1,7 +1,438 @@
 package main
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stiffinWanjohi/go-ecommerce/account"
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+	"github.com/stiffinWanjohi/go-ecommerce/cart"
+	"github.com/stiffinWanjohi/go-ecommerce/catalog"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+	"github.com/stiffinWanjohi/go-ecommerce/order"
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
+)
+
 type Account struct {
-	ID     string  `json:"id"`
-	Name   string  `json:"name"`
-	Orders []Order `json:"orders"`
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	DeletedAt *time.Time       `json:"deletedAt"`
+	Orders    []Order          `json:"orders"`
+	Addresses []AccountAddress `json:"addresses"`
+}
+
+func newAccount(a account.Account) *Account {
+	return &Account{
+		ID:        a.ID,
+		Name:      a.Name,
+		DeletedAt: a.DeletedAt,
+	}
+}
+
+type Category struct {
+	ID       string      `json:"id"`
+	Slug     string      `json:"slug"`
+	Name     string      `json:"name"`
+	Position int         `json:"position"`
+	Parent   *Category   `json:"parent"`
+	Children []*Category `json:"children"`
+}
+
+// newCategoryTree links the categories, listed siblings in order, into a
+// tree and returns its roots.
+func newCategoryTree(categories []catalog.Category) []*Category {
+	nodes := map[string]*Category{}
+	for _, c := range categories {
+		nodes[c.ID] = &Category{
+			ID:       c.ID,
+			Slug:     c.Slug,
+			Name:     c.Name,
+			Position: int(c.Position),
+			Children: []*Category{},
+		}
+	}
+
+	roots := []*Category{}
+	for _, c := range categories {
+		node := nodes[c.ID]
+		parent, ok := nodes[c.ParentID]
+		if !ok {
+			roots = append(roots, node)
+			continue
+		}
+		node.Parent = parent
+		parent.Children = append(parent.Children, node)
+	}
+	return roots
+}
+
+// findCategory returns the first category of the trees below roots that
+// matches, or nil.
+func findCategory(roots []*Category, match func(*Category) bool) *Category {
+	pending := roots
+	for len(pending) > 0 {
+		c := pending[0]
+		if match(c) {
+			return c
+		}
+		pending = append(pending[1:], c.Children...)
+	}
+	return nil
+}
+
+func newSession(s account.Session) *Session {
+	return &Session{
+		Token:     s.Token,
+		ExpiresAt: s.ExpiresAt,
+		Account:   newAccount(s.Account),
+	}
+}
+
+// value dereferences an optional string argument.
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func newMoney(m money.Money) *Money {
+	return &Money{
+		Amount:   m.Decimal(),
+		Currency: m.Currency,
+	}
+}
+
+func newProduct(p catalog.Product) *Product {
+	product := &Product{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       newMoney(p.Price),
+		Stock:       int(p.Available()),
+		TaxCategory: p.TaxCategory,
+		CategoryIds: append([]string{}, p.CategoryIDs...),
+		Options:     newProductOptions(p.Options),
+		Variants:    newProductVariants(p.Variants),
+		ArchivedAt:  p.ArchivedAt,
+	}
+	if !p.CreatedAt.IsZero() {
+		product.CreatedAt = &p.CreatedAt
+	}
+	return product
+}
+
+func newProductOptions(options []catalog.Option) []*ProductOption {
+	out := []*ProductOption{}
+	for _, o := range options {
+		out = append(out, &ProductOption{
+			Name:   o.Name,
+			Values: append([]string{}, o.Values...),
+		})
+	}
+	return out
+}
+
+func newProductVariants(variants []catalog.Variant) []*ProductVariant {
+	out := []*ProductVariant{}
+	for _, v := range variants {
+		options := []*OptionValue{}
+		for _, o := range v.Options {
+			options = append(options, &OptionValue{
+				Name:  o.Name,
+				Value: o.Value,
+			})
+		}
+		out = append(out, &ProductVariant{
+			ID:      v.ID,
+			Sku:     v.SKU,
+			Options: options,
+			Title:   v.Title(),
+			Price:   newMoney(v.Price),
+			Stock:   int(v.Available()),
+		})
+	}
+	return out
+}
+
+func productOptions(in []*ProductOptionInput) []catalog.Option {
+	options := []catalog.Option{}
+	for _, o := range in {
+		options = append(options, catalog.Option{
+			Name:   o.Name,
+			Values: o.Values,
+		})
+	}
+	return options
+}
+
+// productVariants converts the variant inputs, pricing them in currency
+// unless they name their own, and records the malformed ones in v. With
+// neither, currency is empty on updates that keep the product's price, the
+// catalog prices them in the stored product's currency.
+func productVariants(
+	v *validation.Error,
+	in []*ProductVariantInput,
+	currency string,
+) []catalog.Variant {
+	variants := []catalog.Variant{}
+	for i, variant := range in {
+		field := fmt.Sprintf("variants[%d]", i)
+		priceCurrency := value(variant.Price.Currency)
+		if priceCurrency == "" {
+			priceCurrency = currency
+		}
+		price, err := money.ParseAmount(variant.Price.Amount)
+		if priceCurrency != "" {
+			price, err = money.Parse(variant.Price.Amount, priceCurrency)
+		}
+		v.Check(!errors.Is(err, money.ErrInvalidCurrency), field+".price.currency", "must be a 3-letter ISO 4217 code")
+		v.Check(!errors.Is(err, money.ErrInvalidAmount), field+".price.amount", "must be a decimal amount in the currency's minor unit precision")
+
+		stock := 0
+		if variant.Stock != nil {
+			stock = *variant.Stock
+		}
+		v.Check(stock >= 0, field+".stock", "must not be negative")
+
+		options := []catalog.OptionValue{}
+		for _, o := range variant.Options {
+			options = append(options, catalog.OptionValue{
+				Name:  o.Name,
+				Value: o.Value,
+			})
+		}
+		variants = append(variants, catalog.Variant{
+			ID:      value(variant.ID),
+			SKU:     variant.Sku,
+			Options: options,
+			Price:   price,
+			Stock:   uint32(stock),
+		})
+	}
+	return variants
+}
+
+func newProductList(products []catalog.Product, total uint64) *ProductList {
+	list := []*Product{}
+	for _, p := range products {
+		list = append(list, newProduct(p))
+	}
+
+	return &ProductList{
+		Products: list,
+		Total:    int(total),
+	}
+}
+
+func newProductSearchResult(r catalog.SearchResult) *ProductSearchResult {
+	hits := []*Product{}
+	for _, p := range r.Products {
+		hits = append(hits, newProduct(p))
+	}
+
+	facets := &ProductFacets{
+		Categories: newFacetValues(r.Facets.Categories),
+		Attributes: []*AttributeFacet{},
+		Prices:     []*PriceRange{},
+		Availability: &AvailabilityFacet{
+			InStock:    int(r.Facets.InStock),
+			OutOfStock: int(r.Facets.OutOfStock),
+		},
+	}
+	for _, a := range r.Facets.Attributes {
+		facets.Attributes = append(facets.Attributes, &AttributeFacet{
+			Name:   a.Name,
+			Values: newFacetValues(a.Values),
+		})
+	}
+	for _, p := range r.Facets.Prices {
+		facets.Prices = append(facets.Prices, &PriceRange{
+			Min: newMoney(p.Min),
+			Max: newMoney(p.Max),
+		})
+	}
+
+	return &ProductSearchResult{
+		Hits:   hits,
+		Total:  int(r.Total),
+		Facets: facets,
+	}
+}
+
+func newFacetValues(values []catalog.FacetValue) []*FacetValue {
+	out := []*FacetValue{}
+	for _, v := range values {
+		out = append(out, &FacetValue{
+			Value: v.Value,
+			Count: int(v.Count),
+		})
+	}
+	return out
+}
+
+func newCart(c cart.Cart) *Cart {
+	items := []*CartItem{}
+	for _, item := range c.Items {
+		line := &CartItem{
+			ProductID:   item.ProductID,
+			Name:        item.Name,
+			Description: item.Description,
+			Price:       newMoney(item.Price),
+			Quantity:    int(item.Quantity),
+			Unavailable: item.Unavailable,
+		}
+		if item.VariantID != "" {
+			line.VariantID = &item.VariantID
+			line.Sku = &item.SKU
+			line.VariantName = &item.VariantName
+		}
+		items = append(items, line)
+	}
+
+	return &Cart{
+		AccountID:  c.AccountID,
+		Items:      items,
+		TotalPrice: newMoney(c.TotalPrice),
+	}
+}
+
+func newOrder(o order.Order) *Order {
+	products := []*OrderedProduct{}
+	for _, p := range o.Products {
+		line := &OrderedProduct{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       newMoney(p.Price),
+			Quantity:    int(p.Quantity),
+		}
+		if p.VariantID != "" {
+			line.VariantID = &p.VariantID
+			line.Sku = &p.SKU
+			line.VariantName = &p.VariantName
+		}
+		products = append(products, line)
+	}
+
+	statusHistory := []*OrderStatusChange{}
+	for _, c := range o.StatusHistory {
+		statusHistory = append(statusHistory, &OrderStatusChange{
+			Status:    newOrderStatus(c.Status),
+			ChangedAt: c.ChangedAt,
+		})
+	}
+
+	adjustments := []*OrderAdjustment{}
+	for _, a := range o.Adjustments {
+		adjustment := &OrderAdjustment{
+			PromotionID: a.PromotionID,
+			Description: a.Description,
+			Amount:      newMoney(a.Amount),
+		}
+		if a.Code != "" {
+			adjustment.Code = &a.Code
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+
+	taxLines := []*TaxLine{}
+	for _, t := range o.TaxLines {
+		taxLines = append(taxLines, &TaxLine{
+			ProductID:    t.ProductID,
+			Category:     t.Category,
+			Jurisdiction: t.Jurisdiction,
+			Rate:         t.Rate.String(),
+			Inclusive:    t.Inclusive,
+			Amount:       newMoney(t.Amount),
+		})
+	}
+
+	var payment *Payment
+	if o.Payment != nil {
+		payment = &Payment{
+			ID:        o.Payment.ID,
+			Status:    PaymentStatus(strings.ToUpper(string(o.Payment.Status))),
+			Amount:    newMoney(o.Payment.Amount),
+			UpdatedAt: o.Payment.UpdatedAt,
+		}
+	}
+
+	return &Order{
+		ID:              o.ID,
+		CreatedAt:       o.CreatedAt,
+		Subtotal:        newMoney(o.Subtotal),
+		Tax:             newMoney(o.TaxTotal),
+		TotalPrice:      newMoney(o.TotalPrice),
+		ShippingAddress: newAddress(o.ShippingAddress),
+		BillingAddress:  newAddress(o.BillingAddress),
+		ShippingMethod:  o.ShippingMethod,
+		ShippingCost:    newMoney(o.ShippingCost),
+		Products:        products,
+		Status:          newOrderStatus(o.Status),
+		StatusHistory:   statusHistory,
+		Payment:         payment,
+		Adjustments:     adjustments,
+		TaxLines:        taxLines,
+	}
+}
+
+func newAddress(a address.Address) *Address {
+	if a.IsZero() {
+		return nil
+	}
+
+	out := &Address{
+		Name:       a.Name,
+		Line1:      a.Line1,
+		City:       a.City,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+	if a.Line2 != "" {
+		out.Line2 = &a.Line2
+	}
+	if a.Region != "" {
+		out.Region = &a.Region
+	}
+	return out
+}
+
+func newAccountAddress(a account.Address) *AccountAddress {
+	return &AccountAddress{
+		ID:      a.ID,
+		Address: newAddress(a.Address),
+	}
+}
+
+func (in *AddressInput) address() address.Address {
+	if in == nil {
+		return address.Address{}
+	}
+	return address.Address{
+		Name:       in.Name,
+		Line1:      in.Line1,
+		Line2:      value(in.Line2),
+		City:       in.City,
+		Region:     value(in.Region),
+		PostalCode: in.PostalCode,
+		Country:    in.Country,
+	}
+}
+
+func newOrderStatus(s order.Status) OrderStatus {
+	return OrderStatus(strings.ToUpper(string(s)))
+}
+
+func (s OrderStatus) status() order.Status {
+	return order.Status(strings.ToLower(string(s)))
+}
+
+func (s *ProductSort) sort() catalog.Sort {
+	if s == nil {
+		return ""
+	}
+	return catalog.Sort(strings.ToLower(string(*s)))
 }
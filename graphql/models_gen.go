@@ -3,38 +3,185 @@
 package main
 
 import (
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 )
 
+type AccountAddress struct {
+	ID      string   `json:"id"`
+	Address *Address `json:"address"`
+}
+
 type AccountInput struct {
-	Name string `json:"name"`
+	Name           string  `json:"name"`
+	IdempotencyKey *string `json:"idempotencyKey,omitempty"`
+}
+
+type Address struct {
+	Name       string  `json:"name"`
+	Line1      string  `json:"line1"`
+	Line2      *string `json:"line2,omitempty"`
+	City       string  `json:"city"`
+	Region     *string `json:"region,omitempty"`
+	PostalCode string  `json:"postalCode"`
+	Country    string  `json:"country"`
+}
+
+type AddressInput struct {
+	Name       string  `json:"name"`
+	Line1      string  `json:"line1"`
+	Line2      *string `json:"line2,omitempty"`
+	City       string  `json:"city"`
+	Region     *string `json:"region,omitempty"`
+	PostalCode string  `json:"postalCode"`
+	Country    string  `json:"country"`
+}
+
+type AttributeFacet struct {
+	Name   string        `json:"name"`
+	Values []*FacetValue `json:"values"`
+}
+
+type AttributeFilterInput struct {
+	// The option name, such as Size
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type AvailabilityFacet struct {
+	InStock    int `json:"inStock"`
+	OutOfStock int `json:"outOfStock"`
+}
+
+type Cart struct {
+	AccountID  string      `json:"accountId"`
+	Items      []*CartItem `json:"items"`
+	TotalPrice *Money      `json:"totalPrice"`
+}
+
+type CartItem struct {
+	ProductID   string  `json:"productId"`
+	VariantID   *string `json:"variantId,omitempty"`
+	Sku         *string `json:"sku,omitempty"`
+	VariantName *string `json:"variantName,omitempty"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       *Money  `json:"price"`
+	Quantity    int     `json:"quantity"`
+	Unavailable bool    `json:"unavailable"`
+}
+
+type CartItemInput struct {
+	AccountID string  `json:"accountId"`
+	ProductID string  `json:"productId"`
+	VariantID *string `json:"variantId,omitempty"`
+	Quantity  int     `json:"quantity"`
+}
+
+type CategoryInput struct {
+	Slug     string  `json:"slug"`
+	Name     string  `json:"name"`
+	ParentID *string `json:"parentId,omitempty"`
+	Position *int    `json:"position,omitempty"`
+}
+
+// Only the given fields are changed. An empty parentId moves the category to
+// the root.
+type CategoryUpdateInput struct {
+	Slug     *string `json:"slug,omitempty"`
+	Name     *string `json:"name,omitempty"`
+	ParentID *string `json:"parentId,omitempty"`
+	Position *int    `json:"position,omitempty"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type Money struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type MoneyInput struct {
+	Amount   string  `json:"amount"`
+	Currency *string `json:"currency,omitempty"`
 }
 
 type Mutation struct {
 }
 
+type OptionValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type OptionValueInput struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
 type Order struct {
-	ID         string            `json:"id"`
-	CreatedAt  time.Time         `json:"createdAt"`
-	TotalPrice float64           `json:"totalPrice"`
-	Products   []*OrderedProduct `json:"products"`
+	ID              string               `json:"id"`
+	CreatedAt       time.Time            `json:"createdAt"`
+	Subtotal        *Money               `json:"subtotal"`
+	Tax             *Money               `json:"tax"`
+	TotalPrice      *Money               `json:"totalPrice"`
+	ShippingAddress *Address             `json:"shippingAddress,omitempty"`
+	BillingAddress  *Address             `json:"billingAddress,omitempty"`
+	ShippingMethod  string               `json:"shippingMethod"`
+	ShippingCost    *Money               `json:"shippingCost"`
+	Products        []*OrderedProduct    `json:"products"`
+	Status          OrderStatus          `json:"status"`
+	StatusHistory   []*OrderStatusChange `json:"statusHistory"`
+	Payment         *Payment             `json:"payment,omitempty"`
+	Adjustments     []*OrderAdjustment   `json:"adjustments"`
+	TaxLines        []*TaxLine           `json:"taxLines"`
+}
+
+type OrderAdjustment struct {
+	PromotionID string  `json:"promotionId"`
+	Code        *string `json:"code,omitempty"`
+	Description string  `json:"description"`
+	Amount      *Money  `json:"amount"`
 }
 
 type OrderInput struct {
-	AccountID string               `json:"accountId"`
-	Products  []*OrderProductInput `json:"products"`
+	AccountID         string               `json:"accountId"`
+	Products          []*OrderProductInput `json:"products"`
+	IdempotencyKey    *string              `json:"idempotencyKey,omitempty"`
+	CouponCodes       []string             `json:"couponCodes,omitempty"`
+	ShippingAddress   *AddressInput        `json:"shippingAddress,omitempty"`
+	ShippingAddressID *string              `json:"shippingAddressId,omitempty"`
+	BillingAddress    *AddressInput        `json:"billingAddress,omitempty"`
+	BillingAddressID  *string              `json:"billingAddressId,omitempty"`
+	// standard, express or pickup; standard if not given
+	ShippingMethod *string `json:"shippingMethod,omitempty"`
 }
 
+// Products with variants are ordered by variantId, id is then optional.
 type OrderProductInput struct {
-	ID       string `json:"id"`
-	Quantity int    `json:"quantity"`
+	ID        *string `json:"id,omitempty"`
+	VariantID *string `json:"variantId,omitempty"`
+	Quantity  int     `json:"quantity"`
+}
+
+type OrderStatusChange struct {
+	Status    OrderStatus `json:"status"`
+	ChangedAt time.Time   `json:"changedAt"`
 }
 
 type OrderedProduct struct {
 	ID          string  `json:"id"`
+	VariantID   *string `json:"variantId,omitempty"`
+	Sku         *string `json:"sku,omitempty"`
+	VariantName *string `json:"variantName,omitempty"`
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Price       *Money  `json:"price"`
 	Quantity    int     `json:"quantity"`
 }
 
@@ -43,18 +190,336 @@ type PaginationInput struct {
 	Take *int `json:"take,omitempty"`
 }
 
+type Payment struct {
+	ID        string        `json:"id"`
+	Status    PaymentStatus `json:"status"`
+	Amount    *Money        `json:"amount"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+type PriceRange struct {
+	Min *Money `json:"min"`
+	Max *Money `json:"max"`
+}
+
 type Product struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       *Money            `json:"price"`
+	Stock       int               `json:"stock"`
+	TaxCategory string            `json:"taxCategory"`
+	CategoryIds []string          `json:"categoryIds"`
+	Options     []*ProductOption  `json:"options"`
+	Variants    []*ProductVariant `json:"variants"`
+	// Not known for products created before it was recorded
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+}
+
+// Facets are counted over all matching products.
+type ProductFacets struct {
+	// The number of products directly in each category, by category ID
+	Categories []*FacetValue `json:"categories"`
+	// The number of products coming in each option value
+	Attributes []*AttributeFacet `json:"attributes"`
+	// The price range in every currency products are priced in
+	Prices       []*PriceRange      `json:"prices"`
+	Availability *AvailabilityFacet `json:"availability"`
+}
+
+// All given filters apply. A product matches categories when it is in any of
+// them or their subcategories, and an attribute when one of its variants has
+// any of the values given.
+type ProductFilterInput struct {
+	Categories []string                `json:"categories,omitempty"`
+	MinPrice   *MoneyInput             `json:"minPrice,omitempty"`
+	MaxPrice   *MoneyInput             `json:"maxPrice,omitempty"`
+	Attributes []*AttributeFilterInput `json:"attributes,omitempty"`
+	InStock    *bool                   `json:"inStock,omitempty"`
 }
 
 type ProductInput struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	Price          *MoneyInput            `json:"price"`
+	Stock          *int                   `json:"stock,omitempty"`
+	TaxCategory    *string                `json:"taxCategory,omitempty"`
+	CategoryIds    []string               `json:"categoryIds,omitempty"`
+	Options        []*ProductOptionInput  `json:"options,omitempty"`
+	Variants       []*ProductVariantInput `json:"variants,omitempty"`
+	IdempotencyKey *string                `json:"idempotencyKey,omitempty"`
+}
+
+// A page of products and the number of all of them.
+type ProductList struct {
+	Products []*Product `json:"products"`
+	// The number of all matching products, not only of those returned
+	Total int `json:"total"`
+}
+
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductOptionInput struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductSearchResult struct {
+	Hits []*Product `json:"hits"`
+	// The number of all matching products, not only of the hits returned
+	Total  int            `json:"total"`
+	Facets *ProductFacets `json:"facets"`
+}
+
+// Only the given fields are changed. stock sets the units on hand, units
+// reserved by pending orders stay reserved. options and variants replace
+// those of the product and are given together; variants with reserved units
+// cannot be removed.
+type ProductUpdateInput struct {
+	Name        *string                `json:"name,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Price       *MoneyInput            `json:"price,omitempty"`
+	Stock       *int                   `json:"stock,omitempty"`
+	TaxCategory *string                `json:"taxCategory,omitempty"`
+	CategoryIds []string               `json:"categoryIds,omitempty"`
+	Options     []*ProductOptionInput  `json:"options,omitempty"`
+	Variants    []*ProductVariantInput `json:"variants,omitempty"`
+}
+
+// A variant is ordered by its id. Its stock is the number of units that can
+// still be ordered.
+type ProductVariant struct {
+	ID      string         `json:"id"`
+	Sku     string         `json:"sku"`
+	Options []*OptionValue `json:"options"`
+	// The option values joined, such as M / Blue
+	Title string `json:"title"`
+	Price *Money `json:"price"`
+	Stock int    `json:"stock"`
+}
+
+// Variants pick one value of each of the product's options, in order. The
+// price currency defaults to the product's.
+type ProductVariantInput struct {
+	// The id of an existing variant to keep, empty for new variants
+	ID      *string             `json:"id,omitempty"`
+	Sku     string              `json:"sku"`
+	Options []*OptionValueInput `json:"options"`
+	Price   *MoneyInput         `json:"price"`
+	Stock   *int                `json:"stock,omitempty"`
 }
 
 type Query struct {
 }
+
+type RegisterInput struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Account   *Account  `json:"account"`
+}
+
+type TaxLine struct {
+	ProductID    string `json:"productId"`
+	Category     string `json:"category"`
+	Jurisdiction string `json:"jurisdiction"`
+	Rate         string `json:"rate"`
+	Inclusive    bool   `json:"inclusive"`
+	Amount       *Money `json:"amount"`
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "PENDING"
+	OrderStatusPaid      OrderStatus = "PAID"
+	OrderStatusFulfilled OrderStatus = "FULFILLED"
+	OrderStatusShipped   OrderStatus = "SHIPPED"
+	OrderStatusDelivered OrderStatus = "DELIVERED"
+	OrderStatusCancelled OrderStatus = "CANCELLED"
+	OrderStatusRefunded  OrderStatus = "REFUNDED"
+)
+
+var AllOrderStatus = []OrderStatus{
+	OrderStatusPending,
+	OrderStatusPaid,
+	OrderStatusFulfilled,
+	OrderStatusShipped,
+	OrderStatusDelivered,
+	OrderStatusCancelled,
+	OrderStatusRefunded,
+}
+
+func (e OrderStatus) IsValid() bool {
+	switch e {
+	case OrderStatusPending, OrderStatusPaid, OrderStatusFulfilled, OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled, OrderStatusRefunded:
+		return true
+	}
+	return false
+}
+
+func (e OrderStatus) String() string {
+	return string(e)
+}
+
+func (e *OrderStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderStatus", str)
+	}
+	return nil
+}
+
+func (e OrderStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PaymentStatus string
+
+const (
+	PaymentStatusAuthorized PaymentStatus = "AUTHORIZED"
+	PaymentStatusCaptured   PaymentStatus = "CAPTURED"
+	PaymentStatusVoided     PaymentStatus = "VOIDED"
+	PaymentStatusRefunded   PaymentStatus = "REFUNDED"
+)
+
+var AllPaymentStatus = []PaymentStatus{
+	PaymentStatusAuthorized,
+	PaymentStatusCaptured,
+	PaymentStatusVoided,
+	PaymentStatusRefunded,
+}
+
+func (e PaymentStatus) IsValid() bool {
+	switch e {
+	case PaymentStatusAuthorized, PaymentStatusCaptured, PaymentStatusVoided, PaymentStatusRefunded:
+		return true
+	}
+	return false
+}
+
+func (e PaymentStatus) String() string {
+	return string(e)
+}
+
+func (e *PaymentStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PaymentStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PaymentStatus", str)
+	}
+	return nil
+}
+
+func (e PaymentStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// The order products are listed in. RELEVANCE, the default, puts the best
+// matches of a query first and the newest products first without one.
+// Products that compare equal are ordered by ID.
+type ProductSort string
+
+const (
+	ProductSortRelevance ProductSort = "RELEVANCE"
+	ProductSortPriceAsc  ProductSort = "PRICE_ASC"
+	ProductSortPriceDesc ProductSort = "PRICE_DESC"
+	ProductSortNewest    ProductSort = "NEWEST"
+	ProductSortName      ProductSort = "NAME"
+)
+
+var AllProductSort = []ProductSort{
+	ProductSortRelevance,
+	ProductSortPriceAsc,
+	ProductSortPriceDesc,
+	ProductSortNewest,
+	ProductSortName,
+}
+
+func (e ProductSort) IsValid() bool {
+	switch e {
+	case ProductSortRelevance, ProductSortPriceAsc, ProductSortPriceDesc, ProductSortNewest, ProductSortName:
+		return true
+	}
+	return false
+}
+
+func (e ProductSort) String() string {
+	return string(e)
+}
+
+func (e *ProductSort) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProductSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProductSort", str)
+	}
+	return nil
+}
+
+func (e ProductSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Role string
+
+const (
+	RoleCustomer Role = "CUSTOMER"
+	RoleAdmin    Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleCustomer,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleCustomer, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
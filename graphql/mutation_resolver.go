@@ -3,14 +3,15 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
+	"github.com/stiffinWanjohi/go-ecommerce/cart"
+	"github.com/stiffinWanjohi/go-ecommerce/catalog"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
 	"github.com/stiffinWanjohi/go-ecommerce/order"
-)
-
-var (
-	ErrInvalidParameter = errors.New("invalid parameter")
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
 )
 
 type mutationResolver struct {
@@ -24,16 +25,45 @@ func (r *mutationResolver) CreateAccount(
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	a, err := r.server.accountClient.PostAccount(ctx, in.Name)
+	a, err := r.server.accountClient.PostAccount(ctx, in.Name, value(in.IdempotencyKey))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newAccount(*a), nil
+}
+
+func (r *mutationResolver) UpdateAccount(
+	ctx context.Context,
+	id string,
+	name string,
+) (*Account, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, err := r.server.accountClient.UpdateAccount(ctx, id, name)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	return &Account{
-		ID:   a.ID,
-		Name: a.Name,
-	}, nil
+	return newAccount(*a), nil
+}
+
+func (r *mutationResolver) DeleteAccount(
+	ctx context.Context,
+	id string,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := r.server.accountClient.DeleteAccount(ctx, id); err != nil {
+		log.Println(err)
+		return false, err
+	}
+
+	return true, nil
 }
 
 func (r *mutationResolver) CreateProduct(
@@ -43,18 +73,213 @@ func (r *mutationResolver) CreateProduct(
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	p, err := r.server.catalogClient.PostProduct(ctx, in.Name, in.Description, in.Price)
+	v := &validation.Error{}
+	price, err := money.Parse(in.Price.Amount, value(in.Price.Currency))
+	v.Check(!errors.Is(err, money.ErrInvalidCurrency), "price.currency", "must be a 3-letter ISO 4217 code")
+	v.Check(!errors.Is(err, money.ErrInvalidAmount), "price.amount", "must be a decimal amount in the currency's minor unit precision")
+
+	stock := 0
+	if in.Stock != nil {
+		stock = *in.Stock
+	}
+	v.Check(stock >= 0, "stock", "must not be negative")
+	variants := productVariants(v, in.Variants, price.Currency)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	p, err := r.server.catalogClient.PostProduct(
+		ctx,
+		in.Name,
+		in.Description,
+		price,
+		uint32(stock),
+		value(in.TaxCategory),
+		in.CategoryIds,
+		productOptions(in.Options),
+		variants,
+		value(in.IdempotencyKey),
+	)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	return &Product{
-		ID:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-	}, nil
+	return newProduct(*p), nil
+}
+
+func (r *mutationResolver) UpdateProduct(
+	ctx context.Context,
+	id string,
+	in ProductUpdateInput,
+) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	product := catalog.Product{ID: id}
+	fields := []string{}
+	v := &validation.Error{}
+	if in.Name != nil {
+		product.Name = *in.Name
+		fields = append(fields, catalog.FieldName)
+	}
+	if in.Description != nil {
+		product.Description = *in.Description
+		fields = append(fields, catalog.FieldDescription)
+	}
+	if in.Price != nil {
+		price, err := money.Parse(in.Price.Amount, value(in.Price.Currency))
+		v.Check(!errors.Is(err, money.ErrInvalidCurrency), "price.currency", "must be a 3-letter ISO 4217 code")
+		v.Check(!errors.Is(err, money.ErrInvalidAmount), "price.amount", "must be a decimal amount in the currency's minor unit precision")
+		product.Price = price
+		fields = append(fields, catalog.FieldPrice)
+	}
+	if in.Stock != nil {
+		v.Check(*in.Stock >= 0, "stock", "must not be negative")
+		product.Stock = uint32(*in.Stock)
+		fields = append(fields, catalog.FieldStock)
+	}
+	if in.TaxCategory != nil {
+		product.TaxCategory = *in.TaxCategory
+		fields = append(fields, catalog.FieldTaxCategory)
+	}
+	if in.CategoryIds != nil {
+		product.CategoryIDs = in.CategoryIds
+		fields = append(fields, catalog.FieldCategoryIDs)
+	}
+	if in.Options != nil || in.Variants != nil {
+		// Variants are checked against the options, so both are replaced
+		v.Check(in.Options != nil, "options", "must be given with variants")
+		v.Check(in.Variants != nil, "variants", "must be given with options")
+		product.Options = productOptions(in.Options)
+		product.Variants = productVariants(v, in.Variants, product.Price.Currency)
+		fields = append(fields, catalog.FieldVariants)
+	}
+	v.Check(len(fields) > 0, "product", "must change at least one field")
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	p, err := r.server.catalogClient.UpdateProduct(ctx, product, fields)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newProduct(*p), nil
+}
+
+func (r *mutationResolver) ArchiveProduct(
+	ctx context.Context,
+	id string,
+) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	p, err := r.server.catalogClient.DeleteProduct(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newProduct(*p), nil
+}
+
+func (r *mutationResolver) CreateCategory(
+	ctx context.Context,
+	in CategoryInput,
+) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	category := catalog.Category{
+		Slug:     in.Slug,
+		Name:     in.Name,
+		ParentID: value(in.ParentID),
+	}
+	if in.Position != nil {
+		category.Position = int32(*in.Position)
+	}
+
+	c, err := r.server.catalogClient.PostCategory(ctx, category)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return r.category(ctx, c.ID)
+}
+
+func (r *mutationResolver) UpdateCategory(
+	ctx context.Context,
+	id string,
+	in CategoryUpdateInput,
+) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	category := catalog.Category{ID: id}
+	fields := []string{}
+	if in.Slug != nil {
+		category.Slug = *in.Slug
+		fields = append(fields, catalog.FieldSlug)
+	}
+	if in.Name != nil {
+		category.Name = *in.Name
+		fields = append(fields, catalog.FieldName)
+	}
+	if in.ParentID != nil {
+		category.ParentID = *in.ParentID
+		fields = append(fields, catalog.FieldParentID)
+	}
+	if in.Position != nil {
+		category.Position = int32(*in.Position)
+		fields = append(fields, catalog.FieldPosition)
+	}
+	v := &validation.Error{}
+	v.Check(len(fields) > 0, "category", "must change at least one field")
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	c, err := r.server.catalogClient.UpdateCategory(ctx, category, fields)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return r.category(ctx, c.ID)
+}
+
+// category returns the category with its place in the tree.
+func (r *mutationResolver) category(
+	ctx context.Context,
+	id string,
+) (*Category, error) {
+	categoryList, err := r.server.catalogClient.GetCategories(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return findCategory(newCategoryTree(categoryList), func(c *Category) bool {
+		return c.ID == id
+	}), nil
+}
+
+func (r *mutationResolver) DeleteCategory(
+	ctx context.Context,
+	id string,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := r.server.catalogClient.DeleteCategory(ctx, id); err != nil {
+		log.Println(err)
+		return false, err
+	}
+
+	return true, nil
 }
 
 func (r *mutationResolver) CreateOrder(
@@ -64,25 +289,294 @@ func (r *mutationResolver) CreateOrder(
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	v := &validation.Error{}
 	var products []order.OrderedProduct
-	for _, p := range in.Products {
-		if p.Quantity <= 0 {
-			return nil, ErrInvalidParameter
-		}
+	for i, p := range in.Products {
+		v.Check(p.Quantity > 0, fmt.Sprintf("products[%d].quantity", i), "must be greater than 0")
 		products = append(products, order.OrderedProduct{
-			ID:       p.ID,
-			Quantity: uint32(p.Quantity),
+			ID:        value(p.ID),
+			VariantID: value(p.VariantID),
+			Quantity:  uint32(p.Quantity),
 		})
 	}
-	o, err := r.server.orderClient.PostOrder(ctx, in.AccountID, products)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	o, err := r.server.orderClient.PostOrder(ctx, order.OrderRequest{
+		AccountID:         in.AccountID,
+		Products:          products,
+		IdempotencyKey:    value(in.IdempotencyKey),
+		CouponCodes:       in.CouponCodes,
+		ShippingAddress:   in.ShippingAddress.address(),
+		ShippingAddressID: value(in.ShippingAddressID),
+		BillingAddress:    in.BillingAddress.address(),
+		BillingAddressID:  value(in.BillingAddressID),
+		ShippingMethod:    value(in.ShippingMethod),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newOrder(*o), nil
+}
+
+func (r *mutationResolver) UpdateOrderStatus(
+	ctx context.Context,
+	id string,
+	status OrderStatus,
+) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	o, err := r.server.orderClient.UpdateOrderStatus(ctx, id, status.status())
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newOrder(*o), nil
+}
+
+func (r *mutationResolver) CancelOrder(
+	ctx context.Context,
+	id string,
+) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	o, err := r.server.orderClient.GetOrder(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if err := authorizeOwner(ctx, o.AccountID); err != nil {
+		return nil, err
+	}
+
+	o, err = r.server.orderClient.CancelOrder(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newOrder(*o), nil
+}
+
+func (r *mutationResolver) AddCartItem(
+	ctx context.Context,
+	in CartItemInput,
+) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	v := &validation.Error{}
+	v.Check(in.Quantity > 0, "quantity", "must be greater than 0")
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	c, err := r.server.cartClient.AddItem(ctx, in.AccountID, in.ProductID, value(in.VariantID), uint32(in.Quantity))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newCart(*c), nil
+}
+
+func (r *mutationResolver) UpdateCartItem(
+	ctx context.Context,
+	in CartItemInput,
+) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	v := &validation.Error{}
+	v.Check(in.Quantity >= 0, "quantity", "must not be negative")
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	c, err := r.server.cartClient.UpdateItem(ctx, in.AccountID, in.ProductID, value(in.VariantID), uint32(in.Quantity))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newCart(*c), nil
+}
+
+func (r *mutationResolver) RemoveCartItem(
+	ctx context.Context,
+	accountID string,
+	productID string,
+	variantID *string,
+) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	c, err := r.server.cartClient.RemoveItem(ctx, accountID, productID, value(variantID))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newCart(*c), nil
+}
+
+func (r *mutationResolver) ClearCart(
+	ctx context.Context,
+	accountID string,
+) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	c, err := r.server.cartClient.ClearCart(ctx, accountID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newCart(*c), nil
+}
+
+func (r *mutationResolver) Checkout(
+	ctx context.Context,
+	accountID string,
+	idempotencyKey *string,
+	couponCodes []string,
+	shippingAddress *AddressInput,
+	shippingAddressID *string,
+	billingAddress *AddressInput,
+	billingAddressID *string,
+	shippingMethod *string,
+) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	orderID, err := r.server.cartClient.Checkout(ctx, cart.CheckoutRequest{
+		AccountID:         accountID,
+		IdempotencyKey:    value(idempotencyKey),
+		CouponCodes:       couponCodes,
+		ShippingAddress:   shippingAddress.address(),
+		ShippingAddressID: value(shippingAddressID),
+		BillingAddress:    billingAddress.address(),
+		BillingAddressID:  value(billingAddressID),
+		ShippingMethod:    value(shippingMethod),
+	})
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
-	return &Order{
-		ID:         o.ID,
-		CreatedAt:  o.CreatedAt,
-		TotalPrice: o.TotalPrice,
-	}, nil
+	o, err := r.server.orderClient.GetOrder(ctx, orderID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newOrder(*o), nil
+}
+
+func (r *mutationResolver) AddAddress(
+	ctx context.Context,
+	accountID string,
+	in AddressInput,
+) (*AccountAddress, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, err := r.server.accountClient.PostAddress(ctx, accountID, in.address())
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newAccountAddress(*a), nil
+}
+
+func (r *mutationResolver) UpdateAddress(
+	ctx context.Context,
+	accountID string,
+	id string,
+	in AddressInput,
+) (*AccountAddress, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, err := r.server.accountClient.UpdateAddress(ctx, accountID, id, in.address())
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newAccountAddress(*a), nil
+}
+
+func (r *mutationResolver) DeleteAddress(
+	ctx context.Context,
+	accountID string,
+	id string,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := r.server.accountClient.DeleteAddress(ctx, accountID, id); err != nil {
+		log.Println(err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) Register(
+	ctx context.Context,
+	in RegisterInput,
+) (*Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	s, err := r.server.accountClient.Register(ctx, in.Name, in.Email, in.Password)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newSession(*s), nil
+}
+
+func (r *mutationResolver) Login(
+	ctx context.Context,
+	email string,
+	password string,
+) (*Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	s, err := r.server.accountClient.Login(ctx, email, password)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newSession(*s), nil
+}
+
+func (r *mutationResolver) ChangePassword(
+	ctx context.Context,
+	accountID string,
+	currentPassword string,
+	newPassword string,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	err := r.server.accountClient.ChangePassword(ctx, accountID, currentPassword, newPassword)
+	if err != nil {
+		log.Println(err)
+		return false, err
+	}
+
+	return true, nil
 }
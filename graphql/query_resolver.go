@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
+
+	"github.com/stiffinWanjohi/go-ecommerce/catalog"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
 )
 
 type queryResolver struct {
@@ -25,10 +30,7 @@ func (r *queryResolver) Accounts(
 			log.Println(err)
 			return nil, err
 		}
-		return []*Account{{
-			ID:   r.ID,
-			Name: r.Name,
-		}}, nil
+		return []*Account{newAccount(*r)}, nil
 	}
 
 	skip, take := uint64(0), uint64(0)
@@ -44,11 +46,7 @@ func (r *queryResolver) Accounts(
 
 	var accounts []*Account
 	for _, a := range accountList {
-		account := &Account{
-			ID:   a.ID,
-			Name: a.Name,
-		}
-		accounts = append(accounts, account)
+		accounts = append(accounts, newAccount(a))
 	}
 
 	return accounts, nil
@@ -58,7 +56,9 @@ func (r *queryResolver) Products(
 	pagination *PaginationInput,
 	query *string,
 	id *string,
-) ([]*Product, error) {
+	category *string,
+	sort *ProductSort,
+) (*ProductList, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
@@ -69,12 +69,7 @@ func (r *queryResolver) Products(
 			log.Println(err)
 			return nil, err
 		}
-		return []*Product{{
-			ID:          r.ID,
-			Name:        r.Name,
-			Description: r.Description,
-			Price:       r.Price,
-		}}, nil
+		return newProductList([]catalog.Product{*r}, 1), nil
 	}
 
 	skip, take := uint64(0), uint64(0)
@@ -86,25 +81,136 @@ func (r *queryResolver) Products(
 	if query != nil {
 		q = *query
 	}
-	productList, err := r.server.catalogClient.GetProducts(ctx, skip, take, nil, q)
+	productList, total, err := r.server.catalogClient.GetProducts(ctx, skip, take, nil, q, value(category), sort.sort())
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newProductList(productList, total), nil
+}
+
+func (r *queryResolver) SearchProducts(
+	ctx context.Context,
+	query *string,
+	filter *ProductFilterInput,
+	pagination *PaginationInput,
+	sort *ProductSort,
+) (*ProductSearchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	q := catalog.SearchQuery{Query: value(query), Sort: sort.sort()}
+	if pagination != nil {
+		q.Skip, q.Take = pagination.bounds()
+	}
+	if filter != nil {
+		v := &validation.Error{}
+		q.Categories = filter.Categories
+		q.MinPrice = priceBound(v, "filter.minPrice", filter.MinPrice)
+		q.MaxPrice = priceBound(v, "filter.maxPrice", filter.MaxPrice)
+		for _, a := range filter.Attributes {
+			q.Attributes = append(q.Attributes, catalog.AttributeFilter{
+				Name:   a.Name,
+				Values: a.Values,
+			})
+		}
+		q.InStock = filter.InStock != nil && *filter.InStock
+		if err := v.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := r.server.catalogClient.Search(ctx, q)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newProductSearchResult(*result), nil
+}
+
+// priceBound parses an optional price filter.
+func priceBound(
+	v *validation.Error,
+	field string,
+	in *MoneyInput,
+) *money.Money {
+	if in == nil {
+		return nil
+	}
+	price, err := money.Parse(in.Amount, value(in.Currency))
+	v.Check(!errors.Is(err, money.ErrInvalidCurrency), field+".currency", "must be a 3-letter ISO 4217 code")
+	v.Check(!errors.Is(err, money.ErrInvalidAmount), field+".amount", "must be a decimal amount in the currency's minor unit precision")
+	return &price
+}
+
+func (r *queryResolver) Categories(ctx context.Context) ([]*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	categoryList, err := r.server.catalogClient.GetCategories(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return newCategoryTree(categoryList), nil
+}
+
+func (r *queryResolver) Category(
+	ctx context.Context,
+	slug string,
+) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	categoryList, err := r.server.catalogClient.GetCategories(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	// Build the whole tree so the category comes with its parents and
+	// children
+	return findCategory(newCategoryTree(categoryList), func(c *Category) bool {
+		return c.Slug == slug
+	}), nil
+}
+
+func (r *queryResolver) Order(
+	ctx context.Context,
+	id string,
+) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	o, err := r.server.orderClient.GetOrder(ctx, id)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
+	if err := authorizeOwner(ctx, o.AccountID); err != nil {
+		return nil, err
+	}
 
-	var products []*Product
-	for _, a := range productList {
-		products = append(products,
-			&Product{
-				ID:          a.ID,
-				Name:        a.Name,
-				Description: a.Description,
-				Price:       a.Price,
-			},
-		)
+	return newOrder(*o), nil
+}
+
+func (r *queryResolver) Cart(
+	ctx context.Context,
+	accountID string,
+) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	c, err := r.server.cartClient.GetCart(ctx, accountID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
 	}
 
-	return products, nil
+	return newCart(*c), nil
 }
 
 func (p PaginationInput) bounds() (uint64, uint64) {
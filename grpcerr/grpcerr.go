@@ -0,0 +1,247 @@
+// Package grpcerr carries typed errors across gRPC calls. Services return
+// status errors with an ErrorInfo reason, clients turn them back into the
+// errors of their package.
+package grpcerr
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log"
+	"net"
+	"strings"
+	"unicode"
+
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const Domain = "go-ecommerce"
+
+// ReasonValidationFailed is reported with the field violations of a
+// *validation.Error.
+const ReasonValidationFailed = "VALIDATION_FAILED"
+
+// Errors matching the status codes, for callers that only care about the
+// kind of failure.
+var (
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrNotFound           = errors.New("not found")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrFailedPrecondition = errors.New("failed precondition")
+	ErrAborted            = errors.New("aborted")
+	ErrUnauthenticated    = errors.New("unauthenticated")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrUnavailable        = errors.New("service unavailable")
+	ErrDeadlineExceeded   = errors.New("deadline exceeded")
+	ErrInternal           = errors.New("internal error")
+)
+
+var codeErrors = map[codes.Code]error{
+	codes.InvalidArgument:    ErrInvalidArgument,
+	codes.NotFound:           ErrNotFound,
+	codes.AlreadyExists:      ErrAlreadyExists,
+	codes.FailedPrecondition: ErrFailedPrecondition,
+	codes.Aborted:            ErrAborted,
+	codes.Unauthenticated:    ErrUnauthenticated,
+	codes.PermissionDenied:   ErrPermissionDenied,
+	codes.Unavailable:        ErrUnavailable,
+	codes.DeadlineExceeded:   ErrDeadlineExceeded,
+	codes.Internal:           ErrInternal,
+}
+
+type (
+	// Reasons maps the reasons a service reports to the errors of the
+	// client package.
+	Reasons map[string]error
+
+	// Error is an error status returned by a service.
+	Error struct {
+		status   *status.Status
+		reason   string
+		metadata map[string]string
+		err      error
+	}
+)
+
+// New returns a status error with the reason attached as ErrorInfo.
+func New(code codes.Code, reason string, msg string) error {
+	return NewWithMetadata(code, reason, msg, nil)
+}
+
+func NewWithMetadata(
+	code codes.Code,
+	reason string,
+	msg string,
+	metadata map[string]string,
+) error {
+	st, err := status.New(code, msg).WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   Domain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return status.Error(code, msg)
+	}
+	return st.Err()
+}
+
+func (e *Error) Error() string {
+	return e.status.Message()
+}
+
+func (e *Error) Code() codes.Code {
+	return e.status.Code()
+}
+
+// Reason is the reason the service gave, or the name of the code if it gave
+// none, such as NOT_FOUND.
+func (e *Error) Reason() string {
+	if e.reason != "" {
+		return e.reason
+	}
+	return CodeName(e.status.Code())
+}
+
+func (e *Error) Metadata() map[string]string {
+	return e.metadata
+}
+
+// GRPCStatus lets the error pass through a server unchanged.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.status
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+func (e *Error) Is(target error) bool {
+	return codeErrors[e.status.Code()] == target
+}
+
+// Invalid returns an INVALID_ARGUMENT status error listing the field
+// violations as BadRequest details.
+func Invalid(v *validation.Error) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, fv := range v.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fv.Field,
+			Description: fv.Description,
+		})
+	}
+
+	st, err := status.New(codes.InvalidArgument, v.Error()).WithDetails(
+		&errdetails.ErrorInfo{Reason: ReasonValidationFailed, Domain: Domain},
+		badRequest,
+	)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, v.Error())
+	}
+	return st.Err()
+}
+
+// FromStatus turns a status error into an *Error wrapping the error its
+// reason maps to, or a *validation.Error for field violations. Other errors
+// are returned as they are.
+func FromStatus(err error, reasons Reasons) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	e := &Error{status: st}
+	violations := &validation.Error{}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.Domain == Domain {
+				e.reason = detail.Reason
+				e.metadata = detail.Metadata
+			}
+		case *errdetails.BadRequest:
+			for _, fv := range detail.FieldViolations {
+				violations.Check(false, fv.Field, "%s", fv.Description)
+			}
+		}
+	}
+	if e.reason != "" {
+		e.err = reasons[e.reason]
+	}
+	if e.err == nil && len(violations.Violations) > 0 {
+		e.err = violations
+	}
+	return e
+}
+
+// CodeName is the stable name of a code, such as FAILED_PRECONDITION.
+func CodeName(code codes.Code) string {
+	var b strings.Builder
+	prev := ' '
+	for _, r := range code.String() {
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+		prev = r
+	}
+	return b.String()
+}
+
+func UnaryClientInterceptor(reasons Reasons) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return FromStatus(invoker(ctx, method, req, reply, cc, opts...), reasons)
+	}
+}
+
+// UnaryServerInterceptor turns errors the handlers did not map into status
+// errors, so no plain error reaches a client as UNKNOWN. Their details are
+// logged rather than returned.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		res, err := handler(ctx, req)
+		if err == nil {
+			return res, nil
+		}
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+
+		var (
+			netErr  net.Error
+			invalid *validation.Error
+		)
+		switch {
+		case errors.As(err, &invalid):
+			return nil, Invalid(invalid)
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, status.Error(codes.DeadlineExceeded, err.Error())
+		case errors.Is(err, context.Canceled):
+			return nil, status.Error(codes.Canceled, err.Error())
+		case errors.Is(err, driver.ErrBadConn), errors.As(err, &netErr):
+			log.Printf("Error in %s: %v", info.FullMethod, err)
+			return nil, status.Error(codes.Unavailable, "storage unavailable")
+		}
+
+		log.Printf("Error in %s: %v", info.FullMethod, err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,35 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// TTL is how long a key is remembered. A retry after that creates a new
+// entity.
+const TTL = 24 * time.Hour
+
+// Reasons the errors are reported with by the services.
+const (
+	ReasonConflict   = "IDEMPOTENCY_KEY_CONFLICT"
+	ReasonInProgress = "IDEMPOTENCY_KEY_IN_PROGRESS"
+)
+
+var (
+	ErrConflict   = errors.New("idempotency key was already used with a different request")
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// Fingerprint hashes the fields that make up a create request, so that a
+// replay can be told apart from a different request reusing the same key.
+// Callers must pass the fields in a stable order.
+func Fingerprint(fields ...string) string {
+	h := sha256.New()
+	for _, f := range fields {
+		h.Write([]byte(f))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,209 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	pb "github.com/stiffinWanjohi/go-ecommerce/money/pb"
+)
+
+const DefaultCurrency = "USD"
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrInvalidCurrency  = errors.New("invalid currency")
+)
+
+// exponents lists ISO 4217 currencies whose minor unit is not 1/100.
+var exponents = map[string]int{
+	"BHD": 3,
+	"BIF": 0,
+	"CLP": 0,
+	"DJF": 0,
+	"GNF": 0,
+	"IQD": 3,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KMF": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"PYG": 0,
+	"RWF": 0,
+	"TND": 3,
+	"UGX": 0,
+	"UYI": 0,
+	"VND": 0,
+	"VUV": 0,
+	"XAF": 0,
+	"XOF": 0,
+	"XPF": 0,
+}
+
+// maxExponent is the largest number of minor unit digits of the
+// currencies above.
+const maxExponent = 3
+
+// Money is an exact amount expressed in the minor units of an ISO 4217
+// currency, e.g. {1999, "USD"} is $19.99.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func New(amount int64, currency string) Money {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return Money{
+		Amount:   amount,
+		Currency: strings.ToUpper(currency),
+	}
+}
+
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Parse reads a decimal string such as "19.99" into minor units of the
+// given currency. More fractional digits than the currency allows is an
+// error rather than a silent rounding.
+func Parse(amount string, currency string) (Money, error) {
+	m := New(0, currency)
+	if len(m.Currency) != 3 {
+		return Money{}, ErrInvalidCurrency
+	}
+
+	var err error
+	m.Amount, err = parseMinor(amount, Exponent(m.Currency))
+	if err != nil {
+		return Money{}, err
+	}
+	return m, nil
+}
+
+// ParseAmount reads a decimal string whose currency is only known later,
+// see In. Until then the amount is kept in units of 10^-maxExponent and
+// the currency is empty.
+func ParseAmount(amount string) (Money, error) {
+	a, err := parseMinor(amount, maxExponent)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: a}, nil
+}
+
+// In returns an amount read by ParseAmount in minor units of the given
+// currency. Amounts that already have a currency are returned as they
+// are.
+func (m Money) In(currency string) (Money, error) {
+	if m.Currency != "" {
+		return m, nil
+	}
+
+	c := New(0, currency)
+	scale := int64(1)
+	for i := Exponent(c.Currency); i < maxExponent; i++ {
+		scale *= 10
+	}
+	if m.Amount%scale != 0 {
+		return Money{}, ErrInvalidAmount
+	}
+	c.Amount = m.Amount / scale
+	return c, nil
+}
+
+func parseMinor(amount string, exponent int) (int64, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return 0, ErrInvalidAmount
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+	r.Mul(r, new(big.Rat).SetInt(scale))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, ErrInvalidAmount
+	}
+	return r.Num().Int64(), nil
+}
+
+// Exponent returns the number of minor unit digits of the currency.
+func Exponent(currency string) int {
+	if e, ok := exponents[strings.ToUpper(currency)]; ok {
+		return e
+	}
+	return 2
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+// Decimal formats the amount in major units, e.g. "19.99".
+func (m Money) Decimal() string {
+	exp := Exponent(m.Currency)
+	if exp == 0 {
+		return fmt.Sprintf("%d", m.Amount)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	scale := int64(1)
+	for i := 0; i < exp; i++ {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, exp, amount%scale)
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func FromProto(p *pb.Money) Money {
+	if p == nil {
+		return Money{}
+	}
+	return Money{
+		Amount:   p.Amount,
+		Currency: p.Currency,
+	}
+}
+
+func (m Money) Proto() *pb.Money {
+	return &pb.Money{
+		Amount:   m.Amount,
+		Currency: m.Currency,
+	}
+}
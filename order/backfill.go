@@ -0,0 +1,54 @@
+package order
+
+import (
+	"context"
+	"log"
+
+	"github.com/stiffinWanjohi/go-ecommerce/catalog"
+)
+
+// backfillBatch is how many products are requested from the catalog at once.
+const backfillBatch = 100
+
+// BackfillSnapshots stores a snapshot of their product on the order lines
+// placed before lines kept one, see migrations/000_product_snapshot.sql.
+// What the product was when it was ordered is not known anymore, so the
+// lines get its current catalog entry. Lines of products the catalog no
+// longer has are left without a snapshot. It returns how many lines were
+// filled in and can be run again.
+func BackfillSnapshots(
+	ctx context.Context,
+	r OrderRepository,
+	catalogClient *catalog.Client,
+) (int64, error) {
+	ids, err := r.GetUnsnapshottedProductIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var filled int64
+	for start := 0; start < len(ids); start += backfillBatch {
+		batch := ids[start:min(start+backfillBatch, len(ids))]
+		products, err := catalogClient.GetProductsByIDs(ctx, batch, nil)
+		if err != nil {
+			return filled, err
+		}
+
+		found := map[string]bool{}
+		for _, p := range products {
+			n, err := r.SnapshotProductLines(ctx, p.ID, p.Name, p.Description, p.Price)
+			if err != nil {
+				return filled, err
+			}
+			filled += n
+			found[p.ID] = true
+		}
+		for _, id := range batch {
+			if !found[id] {
+				log.Println("Product not in the catalog, lines left without a snapshot: ", id)
+			}
+		}
+	}
+
+	return filled, nil
+}
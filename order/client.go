@@ -3,22 +3,47 @@ package order
 import (
 	"context"
 	"log"
-	"time"
 
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
+	"github.com/stiffinWanjohi/go-ecommerce/grpcerr"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
 	pb "github.com/stiffinWanjohi/go-ecommerce/order/pb"
+	"github.com/stiffinWanjohi/go-ecommerce/promotion"
+	"github.com/stiffinWanjohi/go-ecommerce/tax"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-type Client struct {
-	conn    *grpc.ClientConn
-	service pb.OrderServiceClient
-}
+type (
+	Client struct {
+		conn    *grpc.ClientConn
+		service pb.OrderServiceClient
+	}
 
-func NewClient(url string) (*Client, error) {
+	// OrderRequest is an order to be placed. Addresses are given either
+	// inline or as the ID of an address book entry of the account.
+	OrderRequest struct {
+		AccountID         string
+		Products          []OrderedProduct
+		IdempotencyKey    string
+		CouponCodes       []string
+		ShippingAddress   address.Address
+		ShippingAddressID string
+		BillingAddress    address.Address
+		BillingAddressID  string
+		ShippingMethod    string
+	}
+)
+
+func NewClient(url string, id *auth.ServiceIdentity) (*Client, error) {
 	conn, err := grpc.NewClient(
 		url,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(
+			auth.UnaryClientInterceptor(id),
+			grpcerr.UnaryClientInterceptor(reasons),
+		),
 	)
 	if err != nil {
 		return nil, err
@@ -35,39 +60,47 @@ func (c *Client) Close() {
 
 func (c *Client) PostOrder(
 	ctx context.Context,
-	accountID string,
-	products []OrderedProduct,
+	o OrderRequest,
 ) (*Order, error) {
 	protoProducts := []*pb.PostOrderRequest_OrderProduct{}
-	for _, p := range products {
+	for _, p := range o.Products {
 		protoProducts = append(protoProducts, &pb.PostOrderRequest_OrderProduct{
 			ProductId: p.ID,
+			VariantId: p.VariantID,
 			Quantity:  p.Quantity,
 		})
 	}
 	r, err := c.service.PostOrder(
 		ctx,
 		&pb.PostOrderRequest{
-			AccountId: accountID,
-			Products:  protoProducts,
+			AccountId:         o.AccountID,
+			Products:          protoProducts,
+			IdempotencyKey:    o.IdempotencyKey,
+			CouponCodes:       o.CouponCodes,
+			ShippingAddress:   o.ShippingAddress.Proto(),
+			ShippingAddressId: o.ShippingAddressID,
+			BillingAddress:    o.BillingAddress.Proto(),
+			BillingAddressId:  o.BillingAddressID,
+			ShippingMethod:    o.ShippingMethod,
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create response order
-	newOrder := r.Order
-	newOrderCreatedAt := time.Time{}
-	newOrderCreatedAt.UnmarshalBinary(newOrder.CreatedAt)
+	return orderFromProto(r.Order), nil
+}
 
-	return &Order{
-		ID:         newOrder.Id,
-		CreatedAt:  newOrderCreatedAt,
-		TotalPrice: newOrder.TotalPrice,
-		AccountID:  newOrder.AccountId,
-		Products:   products,
-	}, nil
+func (c *Client) GetOrder(ctx context.Context, id string) (*Order, error) {
+	r, err := c.service.GetOrder(ctx, &pb.GetOrderRequest{
+		Id: id,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return orderFromProto(r.Order), nil
 }
 
 func (c *Client) GetOrdersForAccount(ctx context.Context, accountID string) ([]Order, error) {
@@ -79,30 +112,110 @@ func (c *Client) GetOrdersForAccount(ctx context.Context, accountID string) ([]O
 		return nil, err
 	}
 
-	// Create response orders
 	orders := []Order{}
 	for _, orderProto := range r.Orders {
-		newOrder := Order{
-			ID:         orderProto.Id,
-			TotalPrice: orderProto.TotalPrice,
-			AccountID:  orderProto.AccountId,
-		}
-		newOrder.CreatedAt = time.Time{}
-		newOrder.CreatedAt.UnmarshalBinary(orderProto.CreatedAt)
-
-		products := []OrderedProduct{}
-		for _, p := range orderProto.Products {
-			products = append(products, OrderedProduct{
-				ID:          p.Id,
-				Quantity:    p.Quantity,
-				Name:        p.Name,
-				Description: p.Description,
-				Price:       p.Price,
-			})
+		orders = append(orders, *orderFromProto(orderProto))
+	}
+	return orders, nil
+}
+
+func (c *Client) UpdateOrderStatus(
+	ctx context.Context,
+	id string,
+	status Status,
+) (*Order, error) {
+	r, err := c.service.UpdateOrderStatus(ctx, &pb.UpdateOrderStatusRequest{
+		Id:     id,
+		Status: status.Proto(),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return orderFromProto(r.Order), nil
+}
+
+func (c *Client) CancelOrder(ctx context.Context, id string) (*Order, error) {
+	r, err := c.service.CancelOrder(ctx, &pb.CancelOrderRequest{
+		Id: id,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return orderFromProto(r.Order), nil
+}
+
+func orderFromProto(orderProto *pb.Order) *Order {
+	order := &Order{
+		ID:              orderProto.Id,
+		Subtotal:        money.FromProto(orderProto.Subtotal),
+		ShippingCost:    money.FromProto(orderProto.ShippingCost),
+		TaxTotal:        money.FromProto(orderProto.TaxTotal),
+		TotalPrice:      money.FromProto(orderProto.TotalPrice),
+		ShippingAddress: address.FromProto(orderProto.ShippingAddress),
+		BillingAddress:  address.FromProto(orderProto.BillingAddress),
+		ShippingMethod:  orderProto.ShippingMethod,
+		AccountID:       orderProto.AccountId,
+		Status:          StatusFromProto(orderProto.Status),
+	}
+	order.CreatedAt.UnmarshalBinary(orderProto.CreatedAt)
+
+	for _, c := range orderProto.StatusHistory {
+		change := StatusChange{Status: StatusFromProto(c.Status)}
+		change.ChangedAt.UnmarshalBinary(c.ChangedAt)
+		order.StatusHistory = append(order.StatusHistory, change)
+	}
+
+	if orderProto.Payment != nil {
+		order.Payment = &Payment{
+			ID:      orderProto.Payment.Id,
+			OrderID: orderProto.Id,
+			Amount:  money.FromProto(orderProto.Payment.Amount),
+			Status:  PaymentStatusFromProto(orderProto.Payment.Status),
 		}
-		newOrder.Products = products
+		order.Payment.CreatedAt.UnmarshalBinary(orderProto.Payment.CreatedAt)
+		order.Payment.UpdatedAt.UnmarshalBinary(orderProto.Payment.UpdatedAt)
+	}
 
-		orders = append(orders, newOrder)
+	for _, a := range orderProto.Adjustments {
+		order.Adjustments = append(order.Adjustments, promotion.Adjustment{
+			PromotionID: a.PromotionId,
+			Code:        a.Code,
+			Description: a.Description,
+			Amount:      money.FromProto(a.Amount),
+		})
 	}
-	return orders, nil
+
+	for _, t := range orderProto.TaxLines {
+		rate, _ := tax.ParseRate(t.Rate)
+		order.TaxLines = append(order.TaxLines, tax.TaxLine{
+			ProductID:    t.ProductId,
+			Category:     t.Category,
+			Jurisdiction: t.Jurisdiction,
+			Rate:         rate,
+			Inclusive:    t.Inclusive,
+			Amount:       money.FromProto(t.Amount),
+		})
+	}
+
+	products := []OrderedProduct{}
+	for _, p := range orderProto.Products {
+		products = append(products, OrderedProduct{
+			ID:          p.Id,
+			VariantID:   p.VariantId,
+			SKU:         p.Sku,
+			VariantName: p.VariantName,
+			Quantity:    p.Quantity,
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       money.FromProto(p.Price),
+			TaxCategory: p.TaxCategory,
+		})
+	}
+	order.Products = products
+
+	return order
 }
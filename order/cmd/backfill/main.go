@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
+	"github.com/stiffinWanjohi/go-ecommerce/catalog"
+	"github.com/stiffinWanjohi/go-ecommerce/order"
+)
+
+type Config struct {
+	DatabaseURL   string `envconfig:"DATABASE_URL"`
+	SessionSecret string `envconfig:"SESSION_SECRET" required:"true"`
+	CatalogURL    string `envconfig:"CATALOG_SERVICE_URL"`
+}
+
+func main() {
+	var cfg Config
+	err := envconfig.Process("", &cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r, err := order.NewPostgresRepository(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Close()
+
+	signer := auth.NewSigner(cfg.SessionSecret, time.Minute)
+	catalogClient, err := catalog.NewClient(cfg.CatalogURL, auth.NewServiceIdentity(signer, "order"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer catalogClient.Close()
+
+	filled, err := order.BackfillSnapshots(context.Background(), r, catalogClient)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Order lines filled in:", filled)
+}
@@ -1,18 +1,40 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
+	"github.com/stiffinWanjohi/go-ecommerce/catalog"
 	"github.com/stiffinWanjohi/go-ecommerce/order"
+	"github.com/stiffinWanjohi/go-ecommerce/promotion"
+	"github.com/stiffinWanjohi/go-ecommerce/tax"
 	"github.com/tinrab/retry"
 )
 
+// serviceTokenTTL is how long the tokens this service presents to others
+// are valid. They are signed for every call.
+const serviceTokenTTL = time.Minute
+
 type Config struct {
-	DatabaseURL string `envconfig:"DATABASE_URL"`
-	AccountURL  string `envconfig:"ACCOUNT_SERVICE_URL"`
-	CatalogURL  string `envconfig:"CATALOG_SERVICE_URL"`
+	DatabaseURL   string `envconfig:"DATABASE_URL"`
+	SessionSecret string `envconfig:"SESSION_SECRET" required:"true"`
+	AccountURL    string `envconfig:"ACCOUNT_SERVICE_URL"`
+	CatalogURL    string `envconfig:"CATALOG_SERVICE_URL"`
+	// OutboxPublisher is either "log" or "memory"
+	OutboxPublisher string        `envconfig:"OUTBOX_PUBLISHER" default:"log"`
+	OutboxFile      string        `envconfig:"OUTBOX_FILE"`
+	OutboxInterval  time.Duration `envconfig:"OUTBOX_INTERVAL" default:"1s"`
+	// ReservationSweepInterval is how often expired stock reservations
+	// are confirmed or released
+	ReservationSweepInterval time.Duration `envconfig:"RESERVATION_SWEEP_INTERVAL" default:"1m"`
+	// TaxConfig is the path of the tax rules file. Without it no tax is
+	// charged.
+	TaxConfig string `envconfig:"TAX_CONFIG"`
 }
 
 func main() {
@@ -32,12 +54,74 @@ func main() {
 	})
 	defer r.Close()
 
+	var promotions promotion.PromotionRepository
+	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
+		promotions, err = promotion.NewPostgresRepository(cfg.DatabaseURL)
+		if err != nil {
+			log.Println(err)
+		}
+		return
+	})
+	defer promotions.Close()
+
+	publisher, err := newPublisher(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	relay := order.NewOutboxRelay(r, publisher, cfg.OutboxInterval)
+	go relay.Run(context.Background())
+
+	signer := auth.NewSigner(cfg.SessionSecret, serviceTokenTTL)
+	catalogClient, err := catalog.NewClient(cfg.CatalogURL, auth.NewServiceIdentity(signer, "order"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer catalogClient.Close()
+	sweeper := order.NewReservationSweeper(r, catalogClient, cfg.ReservationSweepInterval)
+	go sweeper.Run(context.Background())
+
+	taxes, err := tax.NewTableCalculator(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.TaxConfig != "" {
+		taxes, err = tax.LoadTableCalculator(cfg.TaxConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	log.Println("Listening on port 8001...")
-	s := order.NewOrderService(r)
+	s := order.NewOrderService(
+		r,
+		order.NewFakePaymentProvider(),
+		promotions,
+		taxes,
+		order.NewFlatRateCalculator(order.DefaultFlatRates),
+	)
 	log.Fatal(order.ListenGRPC(
 		s,
 		cfg.AccountURL,
 		cfg.CatalogURL,
+		signer,
 		8001),
 	)
 }
+
+func newPublisher(cfg Config) (order.Publisher, error) {
+	switch cfg.OutboxPublisher {
+	case "memory":
+		return order.NewMemoryPublisher(), nil
+	case "log":
+		if cfg.OutboxFile == "" {
+			return order.NewLogPublisher(os.Stdout), nil
+		}
+		f, err := os.OpenFile(cfg.OutboxFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		return order.NewLogPublisher(f), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", cfg.OutboxPublisher)
+	}
+}
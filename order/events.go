@@ -0,0 +1,94 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	EventOrderPlaced = "order.placed"
+)
+
+type (
+	// Event is a domain event written to the outbox in the same
+	// transaction as the change it describes.
+	Event struct {
+		ID          string          `json:"id"`
+		Type        string          `json:"type"`
+		AggregateID string          `json:"aggregate_id"`
+		Payload     json.RawMessage `json:"payload"`
+		CreatedAt   time.Time       `json:"created_at"`
+	}
+
+	// Publisher delivers outbox events downstream. Delivery is at least
+	// once, so consumers must tolerate the same event ID twice.
+	Publisher interface {
+		Publish(ctx context.Context, e Event) error
+	}
+
+	// MemoryPublisher keeps published events in memory, for tests and
+	// local runs.
+	MemoryPublisher struct {
+		mu     sync.Mutex
+		events []Event
+	}
+
+	// LogPublisher writes every event as a JSON line.
+	LogPublisher struct {
+		mu sync.Mutex
+		w  io.Writer
+	}
+)
+
+func newOrderPlacedEvent(o Order) (Event, error) {
+	payload, err := json.Marshal(o)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:          ksuid.New().String(),
+		Type:        EventOrderPlaced,
+		AggregateID: o.ID,
+		Payload:     payload,
+		CreatedAt:   o.CreatedAt,
+	}, nil
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, e Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+	return nil
+}
+
+func (p *MemoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Event{}, p.events...)
+}
+
+func NewLogPublisher(w io.Writer) *LogPublisher {
+	return &LogPublisher{w: w}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, e Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.w.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,8 @@
+-- Order lines keep the name, description and unit price their product had
+-- when it was ordered. The products live in the catalog's Elasticsearch
+-- index, so the lines of older orders cannot be backfilled here. They keep
+-- an empty name and a zero price until order/cmd/backfill fills them in
+-- from the product's current catalog entry.
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS name TEXT NOT NULL DEFAULT '';
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS price MONEY NOT NULL DEFAULT 0;
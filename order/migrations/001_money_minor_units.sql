@@ -0,0 +1,21 @@
+-- Converts MONEY columns to integer minor units plus an ISO 4217 currency.
+-- Existing rows were written in the database's lc_monetary currency, which
+-- for every deployment so far has been USD (two decimal places).
+BEGIN;
+
+ALTER TABLE orders
+    ALTER COLUMN total_price TYPE BIGINT USING ROUND(total_price::numeric * 100)::BIGINT,
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Databases created before order lines kept their price have no price
+-- column yet, see 000_product_snapshot.sql
+ALTER TABLE order_products
+    ADD COLUMN IF NOT EXISTS price MONEY NOT NULL DEFAULT 0;
+
+ALTER TABLE order_products
+    ALTER COLUMN price DROP DEFAULT,
+    ALTER COLUMN price TYPE BIGINT USING ROUND(price::numeric * 100)::BIGINT,
+    ALTER COLUMN price SET DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+COMMIT;
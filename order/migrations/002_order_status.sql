@@ -0,0 +1,21 @@
+-- Adds the order lifecycle status and its history. Orders placed before
+-- statuses existed are treated as pending.
+BEGIN;
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'pending';
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_id CHAR(27) NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_id_idx ON order_status_history (order_id);
+
+INSERT INTO order_status_history (order_id, status, changed_at)
+SELECT id, status, created_at FROM orders o
+WHERE NOT EXISTS (SELECT 1 FROM order_status_history h WHERE h.order_id = o.id);
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Links orders to the catalog stock reservation taken when they were
+-- placed. Older orders never reserved stock and keep an empty value.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS reservation_id VARCHAR(27) NOT NULL DEFAULT '';
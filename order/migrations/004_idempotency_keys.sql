@@ -0,0 +1,9 @@
+-- Remembers the order created for a client supplied idempotency key.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    account_id CHAR(27) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    order_id CHAR(27) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (account_id, key)
+);
@@ -0,0 +1,13 @@
+-- Events written in the same transaction as the order and relayed to the
+-- publisher afterwards.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    seq BIGSERIAL PRIMARY KEY,
+    id CHAR(27) NOT NULL UNIQUE,
+    type VARCHAR(64) NOT NULL,
+    aggregate_id CHAR(27) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx ON outbox_events (seq) WHERE published_at IS NULL;
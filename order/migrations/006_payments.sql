@@ -0,0 +1,12 @@
+-- One payment per order, authorized when the order is placed. Orders
+-- placed before this migration have none.
+CREATE TABLE IF NOT EXISTS payments (
+    id CHAR(27) PRIMARY KEY,
+    order_id CHAR(27) NOT NULL UNIQUE REFERENCES orders (id) ON DELETE CASCADE,
+    reference VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
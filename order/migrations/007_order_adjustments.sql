@@ -0,0 +1,13 @@
+-- Discounts applied to orders by promotions. The promotions themselves are
+-- created by promotion/promotion.sql.
+CREATE TABLE IF NOT EXISTS order_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    order_id CHAR(27) NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    promotion_id CHAR(27) NOT NULL,
+    code VARCHAR(64) NOT NULL DEFAULT '',
+    description TEXT NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_adjustments_order_id_idx ON order_adjustments (order_id);
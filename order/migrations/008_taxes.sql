@@ -0,0 +1,26 @@
+-- Orders keep their subtotal, tax and shipping address separately from the
+-- total. Existing orders were untaxed, so their subtotal is the sum of
+-- their lines, or their total when lines predate price snapshots.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_total BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_address JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS tax_category VARCHAR(64) NOT NULL DEFAULT 'standard';
+
+UPDATE orders o SET subtotal = CASE
+    WHEN EXISTS (SELECT 1 FROM order_products op WHERE op.order_id = o.id AND op.name = '') THEN o.total_price
+    ELSE (SELECT COALESCE(SUM(op.price * op.quantity), 0) FROM order_products op WHERE op.order_id = o.id)
+END;
+
+CREATE TABLE IF NOT EXISTS order_tax_lines (
+    id BIGSERIAL PRIMARY KEY,
+    order_id CHAR(27) NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id CHAR(27) NOT NULL,
+    category VARCHAR(64) NOT NULL,
+    jurisdiction VARCHAR(16) NOT NULL,
+    rate BIGINT NOT NULL,
+    inclusive BOOLEAN NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_tax_lines_order_id_idx ON order_tax_lines (order_id);
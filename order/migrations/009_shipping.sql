@@ -0,0 +1,5 @@
+-- Orders snapshot their billing address and record the shipping method and
+-- its cost.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_cost BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS billing_address JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_method VARCHAR(32) NOT NULL DEFAULT '';
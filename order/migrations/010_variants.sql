@@ -0,0 +1,9 @@
+-- Order lines can be for a variant of a product and keep its SKU and name.
+-- Lines without a variant have an empty variant_id, so that several
+-- variants of one product can be ordered together.
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS variant_id VARCHAR(27) NOT NULL DEFAULT '';
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS sku VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE order_products ADD COLUMN IF NOT EXISTS variant_name TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE order_products DROP CONSTRAINT IF EXISTS order_products_pkey;
+ALTER TABLE order_products ADD PRIMARY KEY (order_id, product_id, variant_id);
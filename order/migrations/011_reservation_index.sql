@@ -0,0 +1,2 @@
+-- Lets the reservation sweeper look up orders by their stock reservation.
+CREATE INDEX IF NOT EXISTS orders_reservation_id_idx ON orders (reservation_id);
@@ -2,12 +2,98 @@ CREATE TABLE IF NOT EXISTS orders (
     id CHAR(27) PRIMARY KEY,
     created_at TIMESTAMP WITH TIME ZONE NOT NULL,
     account_id CHAR(27) NOT NULL,
-    total_price MONEY NOT NULL
+    total_price BIGINT NOT NULL,
+    subtotal BIGINT NOT NULL DEFAULT 0,
+    shipping_cost BIGINT NOT NULL DEFAULT 0,
+    tax_total BIGINT NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    shipping_address JSONB NOT NULL DEFAULT '{}',
+    billing_address JSONB NOT NULL DEFAULT '{}',
+    shipping_method VARCHAR(32) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    reservation_id VARCHAR(27) NOT NULL DEFAULT ''
 );
 
+CREATE INDEX IF NOT EXISTS orders_reservation_id_idx ON orders (reservation_id);
+
 CREATE TABLE IF NOT EXISTS order_products (
     order_id CHAR(27) REFERENCES orders (id) ON DELETE CASCADE,
     product_id CHAR(27),
+    variant_id VARCHAR(27) NOT NULL DEFAULT '',
+    sku VARCHAR(64) NOT NULL DEFAULT '',
+    variant_name TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    price BIGINT NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    tax_category VARCHAR(64) NOT NULL DEFAULT 'standard',
     quantity INT NOT NULL,
-    PRIMARY KEY (product_id, order_id)
-);
\ No newline at end of file
+    PRIMARY KEY (order_id, product_id, variant_id)
+);
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_id CHAR(27) NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_id_idx ON order_status_history (order_id);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    account_id CHAR(27) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    order_id CHAR(27) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (account_id, key)
+);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    seq BIGSERIAL PRIMARY KEY,
+    id CHAR(27) NOT NULL UNIQUE,
+    type VARCHAR(64) NOT NULL,
+    aggregate_id CHAR(27) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx ON outbox_events (seq) WHERE published_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS payments (
+    id CHAR(27) PRIMARY KEY,
+    order_id CHAR(27) NOT NULL UNIQUE REFERENCES orders (id) ON DELETE CASCADE,
+    reference VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS order_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    order_id CHAR(27) NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    promotion_id CHAR(27) NOT NULL,
+    code VARCHAR(64) NOT NULL DEFAULT '',
+    description TEXT NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_adjustments_order_id_idx ON order_adjustments (order_id);
+
+CREATE TABLE IF NOT EXISTS order_tax_lines (
+    id BIGSERIAL PRIMARY KEY,
+    order_id CHAR(27) NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id CHAR(27) NOT NULL,
+    category VARCHAR(64) NOT NULL,
+    jurisdiction VARCHAR(16) NOT NULL,
+    rate BIGINT NOT NULL,
+    inclusive BOOLEAN NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_tax_lines_order_id_idx ON order_tax_lines (order_id);
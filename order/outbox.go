@@ -0,0 +1,57 @@
+package order
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// OutboxRelay periodically publishes outbox events that have not been
+// published yet. An event is only marked as published after the publisher
+// accepted it, so a crash in between publishes it again.
+type OutboxRelay struct {
+	repository OrderRepository
+	publisher  Publisher
+	interval   time.Duration
+	batchSize  int
+}
+
+func NewOutboxRelay(r OrderRepository, p Publisher, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		repository: r,
+		publisher:  p,
+		interval:   interval,
+		batchSize:  100,
+	}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.drain(ctx)
+		}
+	}
+}
+
+func (r *OutboxRelay) drain(ctx context.Context) {
+	for {
+		n, err := r.repository.ProcessOutbox(ctx, r.batchSize, func(e Event) error {
+			return r.publisher.Publish(ctx, e)
+		})
+		if err != nil {
+			log.Println("Error relaying outbox events: ", err)
+			return
+		}
+
+		if n < r.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+	pb "github.com/stiffinWanjohi/go-ecommerce/order/pb"
+)
+
+var (
+	ErrPaymentDeclined = errors.New("payment declined")
+)
+
+type PaymentStatus string
+
+const (
+	PaymentAuthorized PaymentStatus = "authorized"
+	PaymentCaptured   PaymentStatus = "captured"
+	PaymentVoided     PaymentStatus = "voided"
+	PaymentRefunded   PaymentStatus = "refunded"
+)
+
+type (
+	// PaymentProvider moves money for orders. An order's amount is
+	// authorized when it is placed and later either captured or voided.
+	// Captured amounts can be refunded.
+	PaymentProvider interface {
+		Authorize(ctx context.Context, orderID string, amount money.Money) (reference string, err error)
+		Capture(ctx context.Context, reference string, amount money.Money) error
+		Void(ctx context.Context, reference string) error
+		Refund(ctx context.Context, reference string, amount money.Money) error
+	}
+
+	Payment struct {
+		ID        string        `json:"id"`
+		OrderID   string        `json:"order_id"`
+		Reference string        `json:"reference"`
+		Amount    money.Money   `json:"amount"`
+		Status    PaymentStatus `json:"status"`
+		CreatedAt time.Time     `json:"created_at"`
+		UpdatedAt time.Time     `json:"updated_at"`
+	}
+
+	// FakePaymentProvider accepts every payment up to DeclineAbove minor
+	// units (all of them when it is zero) and derives references from the
+	// order ID, so the same input always gives the same result.
+	FakePaymentProvider struct {
+		DeclineAbove int64
+	}
+)
+
+func NewFakePaymentProvider() *FakePaymentProvider {
+	return &FakePaymentProvider{}
+}
+
+func (p *FakePaymentProvider) Authorize(
+	_ context.Context,
+	orderID string,
+	amount money.Money,
+) (string, error) {
+	if amount.IsNegative() || (p.DeclineAbove > 0 && amount.Amount > p.DeclineAbove) {
+		return "", ErrPaymentDeclined
+	}
+	return "fake_" + orderID, nil
+}
+
+func (p *FakePaymentProvider) Capture(_ context.Context, reference string, _ money.Money) error {
+	return p.check(reference)
+}
+
+func (p *FakePaymentProvider) Void(_ context.Context, reference string) error {
+	return p.check(reference)
+}
+
+func (p *FakePaymentProvider) Refund(_ context.Context, reference string, _ money.Money) error {
+	return p.check(reference)
+}
+
+func (p *FakePaymentProvider) check(reference string) error {
+	if !strings.HasPrefix(reference, "fake_") {
+		return fmt.Errorf("unknown payment reference %q", reference)
+	}
+	return nil
+}
+
+// settle moves the payment along with an order that moves to next:
+// capturing it when the order is paid, and voiding or refunding it when
+// the order is cancelled or refunded.
+func (p *Payment) settle(
+	ctx context.Context,
+	provider PaymentProvider,
+	next Status,
+) (bool, error) {
+	var (
+		err    error
+		status PaymentStatus
+	)
+	switch {
+	case next == StatusPaid && p.Status == PaymentAuthorized:
+		err = provider.Capture(ctx, p.Reference, p.Amount)
+		status = PaymentCaptured
+	case (next == StatusCancelled || next == StatusRefunded) && p.Status == PaymentAuthorized:
+		err = provider.Void(ctx, p.Reference)
+		status = PaymentVoided
+	case (next == StatusCancelled || next == StatusRefunded) && p.Status == PaymentCaptured:
+		err = provider.Refund(ctx, p.Reference, p.Amount)
+		status = PaymentRefunded
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	p.Status = status
+	p.UpdatedAt = time.Now()
+	return true, nil
+}
+
+var paymentStatusToProto = map[PaymentStatus]pb.PaymentStatus{
+	PaymentAuthorized: pb.PaymentStatus_PAYMENT_STATUS_AUTHORIZED,
+	PaymentCaptured:   pb.PaymentStatus_PAYMENT_STATUS_CAPTURED,
+	PaymentVoided:     pb.PaymentStatus_PAYMENT_STATUS_VOIDED,
+	PaymentRefunded:   pb.PaymentStatus_PAYMENT_STATUS_REFUNDED,
+}
+
+func (s PaymentStatus) Proto() pb.PaymentStatus {
+	return paymentStatusToProto[s]
+}
+
+func PaymentStatusFromProto(p pb.PaymentStatus) PaymentStatus {
+	for s, sp := range paymentStatusToProto {
+		if sp == p {
+			return s
+		}
+	}
+	return ""
+}
@@ -3,16 +3,38 @@ package order
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
 	"time"
 
 	"github.com/lib/pq"
+	"github.com/stiffinWanjohi/go-ecommerce/idempotency"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+	"github.com/stiffinWanjohi/go-ecommerce/promotion"
+	"github.com/stiffinWanjohi/go-ecommerce/tax"
+)
+
+var (
+	ErrNotFound              = errors.New("entity not found")
+	ErrStatusChanged         = errors.New("order status changed concurrently")
+	ErrIdempotencyKeyClaimed = errors.New("idempotency key already claimed")
+	ErrPromotionLimitReached = errors.New("promotion usage limit reached")
 )
 
 type (
 	OrderRepository interface {
 		Close()
 		PutOrder(ctx context.Context, o Order) error
+		GetOrderByID(ctx context.Context, id string) (*Order, error)
 		GetOrdersForAccount(ctx context.Context, accountID string) ([]Order, error)
+		UpdateOrderStatus(ctx context.Context, id string, from Status, to Status, changedAt time.Time, settle func() (*Payment, error)) error
+		CountPromotionUses(ctx context.Context, accountID string) (map[string]int, error)
+		GetPlacedReservations(ctx context.Context, reservationIDs []string) (map[string]bool, error)
+		GetUnsnapshottedProductIDs(ctx context.Context) ([]string, error)
+		SnapshotProductLines(ctx context.Context, productID string, name string, description string, price money.Money) (int64, error)
+		GetIdempotencyKey(ctx context.Context, accountID string, key string) (orderID string, fingerprint string, err error)
+		ProcessOutbox(ctx context.Context, limit int, handle func(Event) error) (int, error)
 	}
 
 	postgresRepository struct {
@@ -55,18 +77,105 @@ func (r *postgresRepository) PutOrder(
 		err = tx.Commit()
 	}()
 
-	tx.ExecContext(
+	if o.IdempotencyKey != "" {
+		err = putIdempotencyKey(ctx, tx, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = checkPromotionLimits(ctx, tx, o.AccountID, o.Adjustments)
+	if err != nil {
+		return err
+	}
+
+	shipping, err := json.Marshal(o.ShippingAddress)
+	if err != nil {
+		return err
+	}
+	billing, err := json.Marshal(o.BillingAddress)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO orders(id, created_at, account_id, total_price, subtotal, shipping_cost, tax_total, currency, 
+		shipping_address, billing_address, shipping_method, status, reservation_id) 
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		o.ID, o.CreatedAt, o.AccountID, o.TotalPrice.Amount, o.Subtotal.Amount, o.ShippingCost.Amount, o.TaxTotal.Amount, o.TotalPrice.Currency,
+		shipping, billing, o.ShippingMethod, o.Status, o.ReservationID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO order_status_history(order_id, status, changed_at) VALUES($1, $2, $3)",
+		o.ID, o.Status, o.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range o.Adjustments {
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO order_adjustments(order_id, promotion_id, code, description, amount, currency) 
+			VALUES($1, $2, $3, $4, $5, $6)`,
+			o.ID, a.PromotionID, a.Code, a.Description, a.Amount.Amount, a.Amount.Currency,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, t := range o.TaxLines {
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO order_tax_lines(order_id, product_id, category, jurisdiction, rate, inclusive, amount, currency) 
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8)`,
+			o.ID, t.ProductID, t.Category, t.Jurisdiction, t.Rate, t.Inclusive, t.Amount.Amount, t.Amount.Currency,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.Payment != nil {
+		err = putPayment(ctx, tx, *o.Payment)
+		if err != nil {
+			return err
+		}
+	}
+
+	event, err := newOrderPlacedEvent(o)
+	if err != nil {
+		return err
+	}
+
+	err = putOutboxEvent(ctx, tx, event)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(
 		ctx,
-		"INSERT INTO orders(id, created_at, account_id, total_price) VALUES($1, $2, $3, $4)",
-		o.ID, o.CreatedAt, o.AccountID, o.TotalPrice,
+		pq.CopyIn(
+			"order_products",
+			"order_id", "product_id", "variant_id", "sku", "variant_name", "name", "description", "price", "currency", "tax_category", "quantity",
+		),
 	)
 	if err != nil {
 		return err
 	}
 
-	stmt, _ := tx.PrepareContext(ctx, pq.CopyIn("order_products", "order_id", "product_id", "quantity"))
 	for _, p := range o.Products {
-		_, err = stmt.ExecContext(ctx, o.ID, p.ID, p.Quantity)
+		_, err = stmt.ExecContext(
+			ctx,
+			o.ID, p.ID, p.VariantID, p.SKU, p.VariantName, p.Name, p.Description, p.Price.Amount, p.Price.Currency, p.TaxCategory, p.Quantity,
+		)
 		if err != nil {
 			return
 		}
@@ -81,6 +190,598 @@ func (r *postgresRepository) PutOrder(
 	return
 }
 
+// putIdempotencyKey records the key of the order being placed. An expired
+// key is taken over, a live one fails with ErrIdempotencyKeyClaimed.
+func putIdempotencyKey(ctx context.Context, tx *sql.Tx, o Order) error {
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO idempotency_keys(account_id, key, fingerprint, order_id, expires_at) 
+		VALUES($1, $2, $3, $4, $5) 
+		ON CONFLICT (account_id, key) DO UPDATE SET 
+		fingerprint = EXCLUDED.fingerprint, 
+		order_id = EXCLUDED.order_id, 
+		expires_at = EXCLUDED.expires_at 
+		WHERE idempotency_keys.expires_at < NOW()`,
+		o.AccountID, o.IdempotencyKey, o.Fingerprint(), o.ID, o.CreatedAt.Add(idempotency.TTL),
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrIdempotencyKeyClaimed
+	}
+
+	return nil
+}
+
+// checkPromotionLimits fails with ErrPromotionLimitReached if the account
+// used up a promotion applied to the order since the order was priced. The
+// promotion rows stay locked until the order is stored, so concurrent
+// orders cannot both take the last use.
+func checkPromotionLimits(
+	ctx context.Context,
+	tx *sql.Tx,
+	accountID string,
+	adjustments []promotion.Adjustment,
+) error {
+	// Lock in a fixed order so that concurrent orders cannot deadlock
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, a := range adjustments {
+		if !seen[a.PromotionID] {
+			seen[a.PromotionID] = true
+			ids = append(ids, a.PromotionID)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		var limit int
+		err := tx.QueryRowContext(
+			ctx,
+			"SELECT per_account_limit FROM promotions WHERE id = $1 FOR UPDATE",
+			id,
+		).Scan(&limit)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && limit == 0) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		var uses int
+		err = tx.QueryRowContext(
+			ctx,
+			`SELECT COUNT(DISTINCT a.order_id) 
+			FROM order_adjustments a 
+			JOIN orders o ON o.id = a.order_id 
+			WHERE a.promotion_id = $1 AND o.account_id = $2 AND o.status <> $3`,
+			id, accountID, StatusCancelled,
+		).Scan(&uses)
+		if err != nil {
+			return err
+		}
+		if uses >= limit {
+			return ErrPromotionLimitReached
+		}
+	}
+
+	return nil
+}
+
+func (r *postgresRepository) GetIdempotencyKey(
+	ctx context.Context,
+	accountID string,
+	key string,
+) (string, string, error) {
+	var orderID, fingerprint string
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT order_id, fingerprint FROM idempotency_keys 
+		WHERE account_id = $1 AND key = $2 AND expires_at >= NOW()`,
+		accountID, key,
+	)
+	if err := row.Scan(&orderID, &fingerprint); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", ErrNotFound
+		}
+		return "", "", err
+	}
+
+	return orderID, fingerprint, nil
+}
+
+func putPayment(ctx context.Context, tx *sql.Tx, p Payment) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO payments(id, order_id, reference, amount, currency, status, created_at, updated_at) 
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)`,
+		p.ID, p.OrderID, p.Reference, p.Amount.Amount, p.Amount.Currency, p.Status, p.CreatedAt, p.UpdatedAt,
+	)
+	return err
+}
+
+func updatePayment(ctx context.Context, tx *sql.Tx, p Payment) error {
+	res, err := tx.ExecContext(
+		ctx,
+		"UPDATE payments SET status = $1, updated_at = $2 WHERE id = $3",
+		p.Status, p.UpdatedAt, p.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// getPayments returns the payments of the given orders keyed by order ID.
+func (r *postgresRepository) getPayments(
+	ctx context.Context,
+	orderIDs []string,
+) (map[string]*Payment, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, order_id, reference, amount, currency, status, created_at, updated_at 
+		FROM payments WHERE order_id = ANY($1)`,
+		pq.Array(orderIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := map[string]*Payment{}
+	for rows.Next() {
+		var (
+			p        Payment
+			amount   int64
+			currency string
+		)
+		if err := rows.Scan(
+			&p.ID,
+			&p.OrderID,
+			&p.Reference,
+			&amount,
+			&currency,
+			&p.Status,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		p.Amount = money.New(amount, currency)
+		payments[p.OrderID] = &p
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// getAdjustments returns the adjustments of the given orders keyed by
+// order ID.
+func (r *postgresRepository) getAdjustments(
+	ctx context.Context,
+	orderIDs []string,
+) (map[string][]promotion.Adjustment, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT order_id, promotion_id, code, description, amount, currency 
+		FROM order_adjustments WHERE order_id = ANY($1) ORDER BY id`,
+		pq.Array(orderIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	adjustments := map[string][]promotion.Adjustment{}
+	for rows.Next() {
+		var (
+			orderID  string
+			a        promotion.Adjustment
+			amount   int64
+			currency string
+		)
+		if err := rows.Scan(
+			&orderID,
+			&a.PromotionID,
+			&a.Code,
+			&a.Description,
+			&amount,
+			&currency,
+		); err != nil {
+			return nil, err
+		}
+		a.Amount = money.New(amount, currency)
+		adjustments[orderID] = append(adjustments[orderID], a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return adjustments, nil
+}
+
+// getTaxLines returns the tax lines of the given orders keyed by order ID.
+func (r *postgresRepository) getTaxLines(
+	ctx context.Context,
+	orderIDs []string,
+) (map[string][]tax.TaxLine, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT order_id, product_id, category, jurisdiction, rate, inclusive, amount, currency 
+		FROM order_tax_lines WHERE order_id = ANY($1) ORDER BY id`,
+		pq.Array(orderIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taxLines := map[string][]tax.TaxLine{}
+	for rows.Next() {
+		var (
+			orderID  string
+			t        tax.TaxLine
+			amount   int64
+			currency string
+		)
+		if err := rows.Scan(
+			&orderID,
+			&t.ProductID,
+			&t.Category,
+			&t.Jurisdiction,
+			&t.Rate,
+			&t.Inclusive,
+			&amount,
+			&currency,
+		); err != nil {
+			return nil, err
+		}
+		t.Amount = money.New(amount, currency)
+		taxLines[orderID] = append(taxLines[orderID], t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return taxLines, nil
+}
+
+// CountPromotionUses counts, per promotion ID, the orders of the account a
+// promotion was applied to. Cancelled orders give their use back.
+func (r *postgresRepository) CountPromotionUses(
+	ctx context.Context,
+	accountID string,
+) (map[string]int, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT a.promotion_id, COUNT(DISTINCT a.order_id) 
+		FROM order_adjustments a 
+		JOIN orders o ON o.id = a.order_id 
+		WHERE o.account_id = $1 AND o.status <> $2 
+		GROUP BY a.promotion_id`,
+		accountID, StatusCancelled,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	uses := map[string]int{}
+	for rows.Next() {
+		var (
+			promotionID string
+			n           int
+		)
+		if err := rows.Scan(&promotionID, &n); err != nil {
+			return nil, err
+		}
+		uses[promotionID] = n
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return uses, nil
+}
+
+// GetPlacedReservations reports which of the given catalog stock
+// reservations an order was placed with.
+func (r *postgresRepository) GetPlacedReservations(
+	ctx context.Context,
+	reservationIDs []string,
+) (map[string]bool, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT reservation_id FROM orders WHERE reservation_id = ANY($1)",
+		pq.Array(reservationIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	placed := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		placed[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return placed, nil
+}
+
+// GetUnsnapshottedProductIDs returns the products of order lines placed
+// before lines kept a snapshot of their product.
+func (r *postgresRepository) GetUnsnapshottedProductIDs(
+	ctx context.Context,
+) ([]string, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT DISTINCT product_id FROM order_products WHERE name = '' ORDER BY product_id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// SnapshotProductLines stores the given product snapshot on the lines of
+// the product that have none and returns how many it changed.
+func (r *postgresRepository) SnapshotProductLines(
+	ctx context.Context,
+	productID string,
+	name string,
+	description string,
+	price money.Money,
+) (int64, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		`UPDATE order_products SET name = $1, description = $2, price = $3, currency = $4 
+		WHERE product_id = $5 AND name = ''`,
+		name, description, price.Amount, price.Currency, productID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func putOutboxEvent(ctx context.Context, tx *sql.Tx, e Event) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO outbox_events(id, type, aggregate_id, payload, created_at) 
+		VALUES($1, $2, $3, $4, $5)`,
+		e.ID, e.Type, e.AggregateID, []byte(e.Payload), e.CreatedAt,
+	)
+	return err
+}
+
+// ProcessOutbox hands up to limit unpublished events, oldest first, to
+// handle and marks the ones it accepted as published. Rows are locked for
+// the duration so concurrent relays skip them instead of publishing twice.
+func (r *postgresRepository) ProcessOutbox(
+	ctx context.Context,
+	limit int,
+	handle func(Event) error,
+) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT id, type, aggregate_id, payload, created_at FROM outbox_events 
+		WHERE published_at IS NULL 
+		ORDER BY seq 
+		LIMIT $1 
+		FOR UPDATE SKIP LOCKED`,
+		limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	events := []Event{}
+	for rows.Next() {
+		var (
+			e       Event
+			payload []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Type, &e.AggregateID, &payload, &e.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Keep what was published so far and retry the rest on the next run
+	n := 0
+	var publishErr error
+	for _, e := range events {
+		if publishErr = handle(e); publishErr != nil {
+			break
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			"UPDATE outbox_events SET published_at = NOW() WHERE id = $1",
+			e.ID,
+		)
+		if err != nil {
+			return 0, err
+		}
+		n++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return n, publishErr
+}
+
+func (r *postgresRepository) GetOrderByID(
+	ctx context.Context,
+	id string,
+) (*Order, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT 
+		o.id, 
+		o.created_at, 
+		o.account_id, 
+		o.total_price, 
+		o.subtotal, 
+		o.shipping_cost, 
+		o.tax_total, 
+		o.currency, 
+		o.shipping_address, 
+		o.billing_address, 
+		o.shipping_method, 
+		o.status, 
+		o.reservation_id, 
+		op.product_id, 
+		op.variant_id, 
+		op.sku, 
+		op.variant_name, 
+		op.name, 
+		op.description, 
+		op.price, 
+		op.currency, 
+		op.tax_category, 
+		op.quantity 
+		FROM orders o 
+		JOIN order_products op ON o.id = op.order_id 
+		WHERE o.id = $1`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orders) == 0 {
+		return nil, ErrNotFound
+	}
+
+	order := &orders[0]
+	history, err := r.getStatusHistory(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	order.StatusHistory = history[id]
+
+	payments, err := r.getPayments(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	order.Payment = payments[id]
+
+	adjustments, err := r.getAdjustments(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	order.Adjustments = adjustments[id]
+
+	taxLines, err := r.getTaxLines(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	order.TaxLines = taxLines[id]
+
+	return order, nil
+}
+
+// getStatusHistory returns the status changes of the given orders, oldest
+// first, keyed by order ID.
+func (r *postgresRepository) getStatusHistory(
+	ctx context.Context,
+	orderIDs []string,
+) (map[string][]StatusChange, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT order_id, status, changed_at FROM order_status_history WHERE order_id = ANY($1) ORDER BY id",
+		pq.Array(orderIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := map[string][]StatusChange{}
+	for rows.Next() {
+		var (
+			orderID string
+			c       StatusChange
+		)
+		if err := rows.Scan(&orderID, &c.Status, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		history[orderID] = append(history[orderID], c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
 func (r *postgresRepository) GetOrdersForAccount(
 	ctx context.Context,
 	accountID string,
@@ -91,8 +792,25 @@ func (r *postgresRepository) GetOrdersForAccount(
 		o.id, 
 		o.created_at, 
 		o.account_id, 
-		o.total_price::numeric::float8, 
+		o.total_price, 
+		o.subtotal, 
+		o.shipping_cost, 
+		o.tax_total, 
+		o.currency, 
+		o.shipping_address, 
+		o.billing_address, 
+		o.shipping_method, 
+		o.status, 
+		o.reservation_id, 
 		op.product_id, 
+		op.variant_id, 
+		op.sku, 
+		op.variant_name, 
+		op.name, 
+		op.description, 
+		op.price, 
+		op.currency, 
+		op.tax_category, 
 		op.quantity 
 		FROM orders o 
 		JOIN order_products op ON o.id = op.order_id 
@@ -105,18 +823,143 @@ func (r *postgresRepository) GetOrdersForAccount(
 	}
 	defer rows.Close()
 
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	orderIDs := []string{}
+	for _, o := range orders {
+		orderIDs = append(orderIDs, o.ID)
+	}
+	history, err := r.getStatusHistory(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := r.getPayments(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	adjustments, err := r.getAdjustments(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	taxLines, err := r.getTaxLines(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		orders[i].StatusHistory = history[orders[i].ID]
+		orders[i].Payment = payments[orders[i].ID]
+		orders[i].Adjustments = adjustments[orders[i].ID]
+		orders[i].TaxLines = taxLines[orders[i].ID]
+	}
+
+	return orders, nil
+}
+
+// UpdateOrderStatus moves the order from one status to another. settle
+// runs while the order is locked in from, and the payment it returns, if
+// any, is stored with the new status. Either both change or neither does.
+func (r *postgresRepository) UpdateOrderStatus(
+	ctx context.Context,
+	id string,
+	from Status,
+	to Status,
+	changedAt time.Time,
+	settle func() (*Payment, error),
+) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Only move the order if nobody else moved it since it was read, and
+	// keep others from moving it until this transaction ends
+	var current Status
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT status FROM orders WHERE id = $1 FOR UPDATE",
+		id,
+	).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if current != from {
+		return ErrStatusChanged
+	}
+
+	payment, err := settle()
+	if err != nil {
+		return err
+	}
+	if payment != nil {
+		err = updatePayment(ctx, tx, *payment)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE orders SET status = $1 WHERE id = $2",
+		to, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO order_status_history(order_id, status, changed_at) VALUES($1, $2, $3)",
+		id, to, changedAt,
+	)
+	return err
+}
+
+// scanOrders folds joined order/order_products rows, sorted by order ID,
+// into orders with their products.
+func scanOrders(rows *sql.Rows) ([]Order, error) {
 	orders := []Order{}
 	var currentOrder *Order
 	currentProducts := []OrderedProduct{}
 
 	for rows.Next() {
 		var (
-			orderID    string
-			createdAt  time.Time
-			accountID  string
-			totalPrice float64
-			productID  string
-			quantity   uint32
+			orderID       string
+			createdAt     time.Time
+			accountID     string
+			totalPrice    int64
+			subtotal      int64
+			shippingCost  int64
+			taxTotal      int64
+			orderCurrency string
+			shipping      []byte
+			billing       []byte
+			method        string
+			status        Status
+			reservationID string
+			productID     string
+			variantID     string
+			sku           string
+			variantName   string
+			name          string
+			desc          string
+			price         int64
+			currency      string
+			taxCategory   string
+			quantity      uint32
 		)
 
 		if err := rows.Scan(
@@ -124,7 +967,24 @@ func (r *postgresRepository) GetOrdersForAccount(
 			&createdAt,
 			&accountID,
 			&totalPrice,
+			&subtotal,
+			&shippingCost,
+			&taxTotal,
+			&orderCurrency,
+			&shipping,
+			&billing,
+			&method,
+			&status,
+			&reservationID,
 			&productID,
+			&variantID,
+			&sku,
+			&variantName,
+			&name,
+			&desc,
+			&price,
+			&currency,
+			&taxCategory,
 			&quantity,
 		); err != nil {
 			return nil, err
@@ -136,17 +996,36 @@ func (r *postgresRepository) GetOrdersForAccount(
 				orders = append(orders, *currentOrder)
 			}
 			currentOrder = &Order{
-				ID:         orderID,
-				AccountID:  accountID,
-				CreatedAt:  createdAt,
-				TotalPrice: totalPrice,
+				ID:             orderID,
+				AccountID:      accountID,
+				CreatedAt:      createdAt,
+				Subtotal:       money.New(subtotal, orderCurrency),
+				ShippingCost:   money.New(shippingCost, orderCurrency),
+				TaxTotal:       money.New(taxTotal, orderCurrency),
+				TotalPrice:     money.New(totalPrice, orderCurrency),
+				ShippingMethod: method,
+				Status:         status,
+				ReservationID:  reservationID,
+			}
+			if err := json.Unmarshal(shipping, &currentOrder.ShippingAddress); err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(billing, &currentOrder.BillingAddress); err != nil {
+				return nil, err
 			}
 			currentProducts = []OrderedProduct{}
 		}
 
 		currentProducts = append(currentProducts, OrderedProduct{
-			ID:       productID,
-			Quantity: quantity,
+			ID:          productID,
+			VariantID:   variantID,
+			SKU:         sku,
+			VariantName: variantName,
+			Name:        name,
+			Description: desc,
+			Price:       money.New(price, currency),
+			TaxCategory: taxCategory,
+			Quantity:    quantity,
 		})
 	}
 
@@ -9,12 +9,48 @@ import (
 	"net"
 
 	account "github.com/stiffinWanjohi/go-ecommerce/account"
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+	addresspb "github.com/stiffinWanjohi/go-ecommerce/address/pb"
+	"github.com/stiffinWanjohi/go-ecommerce/auth"
 	catalog "github.com/stiffinWanjohi/go-ecommerce/catalog"
+	"github.com/stiffinWanjohi/go-ecommerce/grpcerr"
+	"github.com/stiffinWanjohi/go-ecommerce/idempotency"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
 	pb "github.com/stiffinWanjohi/go-ecommerce/order/pb"
+	"github.com/stiffinWanjohi/go-ecommerce/promotion"
+	"github.com/stiffinWanjohi/go-ecommerce/validation"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
+const (
+	reasonOrderNotFound         = "ORDER_NOT_FOUND"
+	reasonInvalidStatus         = "INVALID_ORDER_STATUS"
+	reasonInvalidTransition     = "INVALID_STATUS_TRANSITION"
+	reasonStatusChanged         = "ORDER_STATUS_CHANGED"
+	reasonPaymentDeclined       = "PAYMENT_DECLINED"
+	reasonUnknownShippingMethod = "UNKNOWN_SHIPPING_METHOD"
+	reasonInvalidCoupon         = "INVALID_COUPON"
+	reasonAccountNotFound       = "ACCOUNT_NOT_FOUND"
+	reasonAccountDeleted        = "ACCOUNT_DELETED"
+	reasonAddressNotFound       = "ADDRESS_NOT_FOUND"
+	reasonInsufficientStock     = "INSUFFICIENT_STOCK"
+	reasonPromotionLimit        = "PROMOTION_LIMIT_REACHED"
+)
+
+// reasons maps the reasons the server reports back to the errors of this
+// package.
+var reasons = grpcerr.Reasons{
+	reasonOrderNotFound:         ErrNotFound,
+	reasonStatusChanged:         ErrStatusChanged,
+	reasonPaymentDeclined:       ErrPaymentDeclined,
+	reasonUnknownShippingMethod: ErrUnknownShippingMethod,
+	reasonPromotionLimit:        ErrPromotionLimitReached,
+	idempotency.ReasonConflict:  idempotency.ErrConflict,
+}
+
 type grpcServer struct {
 	pb.UnimplementedOrderServiceServer
 	orderService  OrderService
@@ -22,18 +58,30 @@ type grpcServer struct {
 	catalogClient *catalog.Client
 }
 
+// GetOrder and CancelOrder only need a caller here, the owner of the order
+// is checked once it is loaded.
+var policy = auth.Policy{
+	pb.OrderService_PostOrder_FullMethodName:           auth.Owner((*pb.PostOrderRequest).GetAccountId),
+	pb.OrderService_GetOrder_FullMethodName:            auth.Authenticated,
+	pb.OrderService_GetOrdersForAccount_FullMethodName: auth.Owner((*pb.GetOrdersForAccountRequest).GetAccountId),
+	pb.OrderService_UpdateOrderStatus_FullMethodName:   auth.RequireRole(auth.RoleAdmin),
+	pb.OrderService_CancelOrder_FullMethodName:         auth.Authenticated,
+}
+
 func ListenGRPC(
 	s OrderService,
 	accountURL,
 	catalogURL string,
+	signer *auth.Signer,
 	port int,
 ) error {
-	accountClient, err := account.NewClient(accountURL)
+	id := auth.NewServiceIdentity(signer, "order")
+	accountClient, err := account.NewClient(accountURL, id)
 	if err != nil {
 		return err
 	}
 
-	catalogClient, err := catalog.NewClient(catalogURL)
+	catalogClient, err := catalog.NewClient(catalogURL, id)
 	if err != nil {
 		accountClient.Close()
 		return err
@@ -46,7 +94,10 @@ func ListenGRPC(
 		return err
 	}
 
-	serv := grpc.NewServer()
+	serv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcerr.UnaryServerInterceptor(),
+		auth.UnaryServerInterceptor(signer, policy),
+	))
 	pb.RegisterOrderServiceServer(serv, &grpcServer{
 		orderService:  s,
 		accountClient: accountClient,
@@ -61,73 +112,316 @@ func (s *grpcServer) PostOrder(
 	ctx context.Context,
 	r *pb.PostOrderRequest,
 ) (*pb.PostOrderResponse, error) {
-	// Check if account exists
-	_, err := s.accountClient.GetAccount(ctx, r.AccountId)
+	if err := validatePostOrder(r); err != nil {
+		return nil, err
+	}
+
+	// Check if account exists and is still open
+	a, err := s.accountClient.GetAccount(ctx, r.AccountId)
+	if errors.Is(err, account.ErrNotFound) {
+		return nil, grpcerr.New(codes.NotFound, reasonAccountNotFound, fmt.Sprintf("account %s not found", r.AccountId))
+	}
 	if err != nil {
 		log.Println("Error getting account: ", err)
-		return nil, errors.New("account not found")
+		return nil, err
+	}
+	if a.DeletedAt != nil {
+		return nil, grpcerr.New(codes.FailedPrecondition, reasonAccountDeleted, fmt.Sprintf("account %s is deleted", r.AccountId))
+	}
+
+	// Snapshot the addresses the order goes to
+	shippingAddress, err := s.resolveAddress(ctx, "shippingAddress", r.AccountId, r.ShippingAddressId, r.ShippingAddress)
+	if err != nil {
+		log.Println("Error resolving shipping address: ", err)
+		return nil, err
+	}
+	billingAddress, err := s.resolveAddress(ctx, "billingAddress", r.AccountId, r.BillingAddressId, r.BillingAddress)
+	if err != nil {
+		log.Println("Error resolving billing address: ", err)
+		return nil, err
+	}
+	if billingAddress.IsZero() {
+		billingAddress = shippingAddress
 	}
 
-	// Get ordered products
+	shippingMethod := r.ShippingMethod
+	if shippingMethod == "" {
+		shippingMethod = DefaultShippingMethod
+	}
+
+	// Replay the original order if this request was already handled
+	requested := Order{
+		AccountID:       r.AccountId,
+		IdempotencyKey:  r.IdempotencyKey,
+		CouponCodes:     r.CouponCodes,
+		ShippingAddress: shippingAddress,
+		BillingAddress:  billingAddress,
+		ShippingMethod:  shippingMethod,
+	}
+	for _, p := range r.Products {
+		requested.Products = append(requested.Products, OrderedProduct{
+			ID:        p.ProductId,
+			VariantID: p.VariantId,
+			Quantity:  p.Quantity,
+		})
+	}
+	replayed, err := s.orderService.GetIdempotentOrder(ctx, requested)
+	if err != nil {
+		log.Println("Error checking idempotency key: ", err)
+		return nil, idempotencyError(err)
+	}
+	if replayed != nil {
+		return &pb.PostOrderResponse{
+			Order: orderToProto(replayed),
+		}, nil
+	}
+
+	// Get ordered products, by their variants where given
 	productIDs := []string{}
+	variantIDs := []string{}
 	for _, p := range r.Products {
+		if p.VariantId != "" {
+			variantIDs = append(variantIDs, p.VariantId)
+			continue
+		}
 		productIDs = append(productIDs, p.ProductId)
 	}
-	orderedProducts, err := s.catalogClient.GetProducts(ctx, 0, 0, productIDs, "")
+	orderedProducts, err := s.catalogClient.GetProductsByIDs(ctx, productIDs, variantIDs)
 	if err != nil {
 		log.Println("Error getting products: ", err)
-		return nil, errors.New("products not found")
+		return nil, err
+	}
+
+	// Construct products, every requested line must be in the catalog
+	products, err := orderLines(r.Products, orderedProducts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reserve stock for every line before the order exists
+	items := []catalog.StockItem{}
+	for _, p := range products {
+		items = append(items, catalog.StockItem{
+			ProductID: p.ID,
+			VariantID: p.VariantID,
+			Quantity:  p.Quantity,
+		})
+	}
+	reservationID, err := s.catalogClient.ReserveStock(ctx, items)
+	if err != nil {
+		log.Println("Error reserving stock: ", err)
+		var stockErr *catalog.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			return nil, grpcerr.NewWithMetadata(
+				codes.FailedPrecondition,
+				reasonInsufficientStock,
+				stockErr.Error(),
+				map[string]string{"product_id": stockErr.ProductID, "variant_id": stockErr.VariantID},
+			)
+		}
+		return nil, err
+	}
+
+	// Call service implementation
+	order, err := s.orderService.PostOrder(ctx, Order{
+		AccountID:       r.AccountId,
+		Products:        products,
+		ReservationID:   reservationID,
+		IdempotencyKey:  r.IdempotencyKey,
+		CouponCodes:     r.CouponCodes,
+		ShippingAddress: shippingAddress,
+		BillingAddress:  billingAddress,
+		ShippingMethod:  shippingMethod,
+	})
+	if err != nil || order.ReservationID != reservationID {
+		// Either nothing was placed or a concurrent replay placed the
+		// order with its own reservation
+		if err := s.catalogClient.ReleaseStock(ctx, reservationID); err != nil {
+			log.Println("Error releasing stock: ", err)
+		}
+	}
+	if err != nil {
+		log.Println("Error posting order: ", err)
+		var codeErr *promotion.CodeError
+		switch {
+		case errors.Is(err, idempotency.ErrConflict):
+			return nil, idempotencyError(err)
+		case errors.Is(err, ErrPaymentDeclined):
+			return nil, grpcerr.New(codes.FailedPrecondition, reasonPaymentDeclined, err.Error())
+		case errors.As(err, &codeErr):
+			return nil, grpcerr.NewWithMetadata(
+				codes.InvalidArgument,
+				reasonInvalidCoupon,
+				codeErr.Error(),
+				map[string]string{"code": codeErr.Code},
+			)
+		case errors.Is(err, money.ErrCurrencyMismatch):
+			v := &validation.Error{}
+			v.Check(false, "products", "must all be priced in one currency")
+			return nil, v.Err()
+		case errors.Is(err, ErrPromotionLimitReached):
+			return nil, grpcerr.New(codes.Aborted, reasonPromotionLimit, err.Error())
+		case errors.Is(err, ErrUnknownShippingMethod):
+			return nil, grpcerr.New(
+				codes.InvalidArgument,
+				reasonUnknownShippingMethod,
+				fmt.Sprintf("%v: %s", err, shippingMethod),
+			)
+		}
+		return nil, err
+	}
+
+	// The order holds the reservation from now on. If confirming fails,
+	// the reservation sweeper confirms it once it expires.
+	if order.ReservationID == reservationID {
+		if err := s.catalogClient.ConfirmReservation(ctx, reservationID); err != nil {
+			log.Println("Error confirming stock reservation: ", err)
+		}
 	}
 
-	// Construct products
+	return &pb.PostOrderResponse{
+		Order: orderToProto(order),
+	}, nil
+}
+
+// validatePostOrder checks the shape of r, whether the products exist is
+// up to the catalog.
+func validatePostOrder(r *pb.PostOrderRequest) error {
+	v := &validation.Error{}
+	v.Check(r.AccountId != "", "accountId", "is required")
+	v.Check(len(r.Products) > 0, "products", "must contain at least one product")
+
+	seen := map[string]bool{}
+	seenVariants := map[string]bool{}
+	for i, p := range r.Products {
+		field := fmt.Sprintf("products[%d]", i)
+		v.Check(p.ProductId != "" || p.VariantId != "", field+".productId", "is required without a variantId")
+		if p.VariantId != "" {
+			v.Check(!seenVariants[p.VariantId], field+".variantId", "duplicates variant %s", p.VariantId)
+			seenVariants[p.VariantId] = true
+		} else if p.ProductId != "" {
+			v.Check(!seen[p.ProductId], field+".productId", "duplicates product %s", p.ProductId)
+			seen[p.ProductId] = true
+		}
+		v.Check(p.Quantity > 0, field+".quantity", "must be greater than 0")
+	}
+	return v.Err()
+}
+
+// orderLines prices the requested lines with the catalog products, in the
+// requested order. Lines whose product or variant the catalog did not
+// return or whose product is archived are reported as violations, as are
+// lines for products with variants that do not name one and lines priced
+// in different currencies.
+func orderLines(
+	requested []*pb.PostOrderRequest_OrderProduct,
+	found []catalog.Product,
+) ([]OrderedProduct, error) {
+	byID := map[string]catalog.Product{}
+	byVariantID := map[string]catalog.Product{}
+	for _, p := range found {
+		byID[p.ID] = p
+		for _, variant := range p.Variants {
+			byVariantID[variant.ID] = p
+		}
+	}
+
+	v := &validation.Error{}
 	products := []OrderedProduct{}
-	for _, p := range orderedProducts {
-		product := OrderedProduct{
+	for i, rp := range requested {
+		field := fmt.Sprintf("products[%d].productId", i)
+		p, ok := byID[rp.ProductId]
+		if rp.VariantId != "" {
+			field = fmt.Sprintf("products[%d].variantId", i)
+			p, ok = byVariantID[rp.VariantId]
+			v.Check(ok, field, "variant %s does not exist", rp.VariantId)
+			if ok && rp.ProductId != "" && rp.ProductId != p.ID {
+				v.Check(false, fmt.Sprintf("products[%d].productId", i), "variant %s is not of product %s", rp.VariantId, rp.ProductId)
+				continue
+			}
+		} else {
+			v.Check(ok, field, "product %s does not exist", rp.ProductId)
+			v.Check(!ok || len(p.Variants) == 0, fmt.Sprintf("products[%d].variantId", i), "is required for product %s, which has variants", rp.ProductId)
+		}
+		v.Check(!ok || p.ArchivedAt == nil, field, "product %s is archived", p.ID)
+		if !ok || p.ArchivedAt != nil {
+			continue
+		}
+
+		line := OrderedProduct{
 			ID:          p.ID,
-			Quantity:    0,
+			Quantity:    rp.Quantity,
 			Price:       p.Price,
 			Name:        p.Name,
 			Description: p.Description,
+			TaxCategory: p.TaxCategory,
 		}
-		for _, rp := range r.Products {
-			if rp.ProductId == p.ID {
-				product.Quantity = rp.Quantity
-				break
-			}
+		if variant, ok := p.Variant(rp.VariantId); ok {
+			line.VariantID = variant.ID
+			line.SKU = variant.SKU
+			line.VariantName = variant.Title()
+			line.Price = variant.Price
 		}
+		products = append(products, line)
+	}
+	for _, line := range products {
+		if line.Price.Currency != products[0].Price.Currency {
+			v.Check(false, "products", "must all be priced in one currency, found %s and %s", products[0].Price.Currency, line.Price.Currency)
+			break
+		}
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
 
-		if product.Quantity != 0 {
-			products = append(products, product)
+// resolveAddress returns the address book entry id of the account or,
+// without an id, the inline address.
+func (s *grpcServer) resolveAddress(
+	ctx context.Context,
+	field string,
+	accountID string,
+	id string,
+	inline *addresspb.Address,
+) (address.Address, error) {
+	if id != "" {
+		entry, err := s.accountClient.GetAddress(ctx, accountID, id)
+		if errors.Is(err, account.ErrNotFound) {
+			return address.Address{}, grpcerr.New(codes.InvalidArgument, reasonAddressNotFound, fmt.Sprintf("address %s not found", id))
+		}
+		if err != nil {
+			return address.Address{}, err
 		}
+		return entry.Address, nil
 	}
 
-	// Call service implementation
-	order, err := s.orderService.PostOrder(ctx, r.AccountId, products)
-	if err != nil {
-		log.Println("Error posting order: ", err)
-		return nil, errors.New("could not post order")
+	a := address.FromProto(inline).Normalize()
+	if a.IsZero() {
+		return a, nil
+	}
+	if err := a.Validate(); err != nil {
+		v := &validation.Error{}
+		v.Merge(field, err)
+		return address.Address{}, v
 	}
+	return a, nil
+}
 
-	// Make response order
-	orderProto := &pb.Order{
-		Id:         order.ID,
-		AccountId:  order.AccountID,
-		TotalPrice: order.TotalPrice,
-		Products:   []*pb.Order_OrderProduct{},
+func (s *grpcServer) GetOrder(
+	ctx context.Context,
+	r *pb.GetOrderRequest,
+) (*pb.GetOrderResponse, error) {
+	order, err := s.orderService.GetOrder(ctx, r.Id)
+	if err != nil {
+		log.Println("Error getting order: ", err)
+		return nil, orderStatusError(r.Id, err)
 	}
-	orderProto.CreatedAt, _ = order.CreatedAt.MarshalBinary()
-	for _, p := range order.Products {
-		orderProto.Products = append(orderProto.Products, &pb.Order_OrderProduct{
-			Id:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-			Quantity:    p.Quantity,
-		})
+	if err := authorizeOrder(ctx, order); err != nil {
+		return nil, err
 	}
-	return &pb.PostOrderResponse{
-		Order: orderProto,
-	}, nil
+
+	return &pb.GetOrderResponse{Order: orderToProto(order)}, nil
 }
 
 func (s *grpcServer) GetOrdersForAccount(
@@ -141,57 +435,174 @@ func (s *grpcServer) GetOrdersForAccount(
 		return nil, err
 	}
 
-	// Get all ordered products
-	productIDMap := map[string]bool{}
+	orders := []*pb.Order{}
 	for _, o := range accountOrders {
-		for _, p := range o.Products {
-			productIDMap[p.ID] = true
-		}
+		orders = append(orders, orderToProto(&o))
 	}
-	productIDs := []string{}
-	for id := range productIDMap {
-		productIDs = append(productIDs, id)
+	return &pb.GetOrdersForAccountResponse{Orders: orders}, nil
+}
+
+func (s *grpcServer) UpdateOrderStatus(
+	ctx context.Context,
+	r *pb.UpdateOrderStatusRequest,
+) (*pb.UpdateOrderStatusResponse, error) {
+	next := StatusFromProto(r.Status)
+	if next == "" {
+		return nil, grpcerr.New(codes.InvalidArgument, reasonInvalidStatus, fmt.Sprintf("unknown order status %s", r.Status))
 	}
-	products, err := s.catalogClient.GetProducts(ctx, 0, 0, productIDs, "")
+
+	order, err := s.orderService.UpdateOrderStatus(ctx, r.Id, next)
 	if err != nil {
-		log.Println("Error getting account products: ", err)
+		log.Println("Error updating order status: ", err)
+		return nil, orderStatusError(r.Id, err)
+	}
+	s.settleStock(ctx, order)
+
+	return &pb.UpdateOrderStatusResponse{Order: orderToProto(order)}, nil
+}
+
+func (s *grpcServer) CancelOrder(
+	ctx context.Context,
+	r *pb.CancelOrderRequest,
+) (*pb.CancelOrderResponse, error) {
+	order, err := s.orderService.GetOrder(ctx, r.Id)
+	if err != nil {
+		log.Println("Error getting order: ", err)
+		return nil, orderStatusError(r.Id, err)
+	}
+	if err := authorizeOrder(ctx, order); err != nil {
 		return nil, err
 	}
 
-	// Construct orders
-	orders := []*pb.Order{}
-	for _, o := range accountOrders {
-		// Encode order
-		op := &pb.Order{
-			AccountId:  o.AccountID,
-			Id:         o.ID,
-			TotalPrice: o.TotalPrice,
-			Products:   []*pb.Order_OrderProduct{},
-		}
-		op.CreatedAt, _ = o.CreatedAt.MarshalBinary()
-
-		// Decorate orders with products
-		for _, product := range o.Products {
-			// Populate product fields
-			for _, p := range products {
-				if p.ID == product.ID {
-					product.Name = p.Name
-					product.Description = p.Description
-					product.Price = p.Price
-					break
-				}
-			}
+	order, err = s.orderService.CancelOrder(ctx, r.Id)
+	if err != nil {
+		log.Println("Error cancelling order: ", err)
+		return nil, orderStatusError(r.Id, err)
+	}
+	s.settleStock(ctx, order)
+
+	return &pb.CancelOrderResponse{Order: orderToProto(order)}, nil
+}
+
+// settleStock commits the order's stock reservation once it is fulfilled
+// and hands the stock back when it is cancelled or refunded before that.
+// Stock of orders refunded after fulfilment has left the warehouse and
+// stays committed.
+func (s *grpcServer) settleStock(ctx context.Context, order *Order) {
+	if order.ReservationID == "" {
+		return
+	}
 
-			op.Products = append(op.Products, &pb.Order_OrderProduct{
-				Id:          product.ID,
-				Name:        product.Name,
-				Description: product.Description,
-				Price:       product.Price,
-				Quantity:    product.Quantity,
-			})
+	var err error
+	switch order.Status {
+	case StatusFulfilled:
+		err = s.catalogClient.CommitStock(ctx, order.ReservationID)
+	case StatusCancelled, StatusRefunded:
+		if !order.HasBeen(StatusFulfilled) {
+			err = s.catalogClient.ReleaseStock(ctx, order.ReservationID)
 		}
+	}
+	if err != nil {
+		log.Printf("Error settling stock reservation %s: %v", order.ReservationID, err)
+	}
+}
 
-		orders = append(orders, op)
+func idempotencyError(err error) error {
+	if errors.Is(err, idempotency.ErrConflict) {
+		return grpcerr.New(codes.AlreadyExists, idempotency.ReasonConflict, err.Error())
 	}
-	return &pb.GetOrdersForAccountResponse{Orders: orders}, nil
+	return err
+}
+
+// authorizeOrder fails unless the caller may see the order.
+func authorizeOrder(ctx context.Context, order *Order) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok || !p.CanAccess(order.AccountID) {
+		return status.Errorf(codes.PermissionDenied, "not allowed to access order %s", order.ID)
+	}
+	return nil
+}
+
+func orderStatusError(id string, err error) error {
+	var transitionErr *InvalidTransitionError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return grpcerr.New(codes.NotFound, reasonOrderNotFound, fmt.Sprintf("order %s not found", id))
+	case errors.As(err, &transitionErr):
+		return grpcerr.New(codes.FailedPrecondition, reasonInvalidTransition, transitionErr.Error())
+	case errors.Is(err, ErrStatusChanged):
+		return grpcerr.New(codes.Aborted, reasonStatusChanged, err.Error())
+	case errors.Is(err, ErrPaymentDeclined):
+		return grpcerr.New(codes.FailedPrecondition, reasonPaymentDeclined, err.Error())
+	}
+	return err
+}
+
+func orderToProto(o *Order) *pb.Order {
+	op := &pb.Order{
+		Id:              o.ID,
+		AccountId:       o.AccountID,
+		Subtotal:        o.Subtotal.Proto(),
+		ShippingCost:    o.ShippingCost.Proto(),
+		TaxTotal:        o.TaxTotal.Proto(),
+		TotalPrice:      o.TotalPrice.Proto(),
+		ShippingAddress: o.ShippingAddress.Proto(),
+		BillingAddress:  o.BillingAddress.Proto(),
+		ShippingMethod:  o.ShippingMethod,
+		Status:          o.Status.Proto(),
+		Products:        []*pb.Order_OrderProduct{},
+	}
+	op.CreatedAt, _ = o.CreatedAt.MarshalBinary()
+
+	for _, c := range o.StatusHistory {
+		change := &pb.Order_StatusChange{Status: c.Status.Proto()}
+		change.ChangedAt, _ = c.ChangedAt.MarshalBinary()
+		op.StatusHistory = append(op.StatusHistory, change)
+	}
+
+	if o.Payment != nil {
+		op.Payment = &pb.Payment{
+			Id:     o.Payment.ID,
+			Status: o.Payment.Status.Proto(),
+			Amount: o.Payment.Amount.Proto(),
+		}
+		op.Payment.CreatedAt, _ = o.Payment.CreatedAt.MarshalBinary()
+		op.Payment.UpdatedAt, _ = o.Payment.UpdatedAt.MarshalBinary()
+	}
+
+	for _, a := range o.Adjustments {
+		op.Adjustments = append(op.Adjustments, &pb.Order_Adjustment{
+			PromotionId: a.PromotionID,
+			Code:        a.Code,
+			Description: a.Description,
+			Amount:      a.Amount.Proto(),
+		})
+	}
+
+	for _, t := range o.TaxLines {
+		op.TaxLines = append(op.TaxLines, &pb.Order_TaxLine{
+			ProductId:    t.ProductID,
+			Category:     t.Category,
+			Jurisdiction: t.Jurisdiction,
+			Rate:         t.Rate.String(),
+			Inclusive:    t.Inclusive,
+			Amount:       t.Amount.Proto(),
+		})
+	}
+
+	for _, p := range o.Products {
+		op.Products = append(op.Products, &pb.Order_OrderProduct{
+			Id:          p.ID,
+			VariantId:   p.VariantID,
+			Sku:         p.SKU,
+			VariantName: p.VariantName,
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       p.Price.Proto(),
+			TaxCategory: p.TaxCategory,
+			Quantity:    p.Quantity,
+		})
+	}
+
+	return op
 }
@@ -2,70 +2,420 @@ package order
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/segmentio/ksuid"
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+	"github.com/stiffinWanjohi/go-ecommerce/idempotency"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+	"github.com/stiffinWanjohi/go-ecommerce/promotion"
+	"github.com/stiffinWanjohi/go-ecommerce/tax"
 )
 
 type (
 	OrderService interface {
-		PostOrder(ctx context.Context, accountId string, products []OrderedProduct) (*Order, error)
+		PostOrder(ctx context.Context, o Order) (*Order, error)
+		GetIdempotentOrder(ctx context.Context, o Order) (*Order, error)
+		GetOrder(ctx context.Context, id string) (*Order, error)
 		GetOrdersForAccount(ctx context.Context, accountId string) ([]Order, error)
+		UpdateOrderStatus(ctx context.Context, id string, status Status) (*Order, error)
+		CancelOrder(ctx context.Context, id string) (*Order, error)
 	}
 
 	Order struct {
-		ID         string           `json:"id"`
-		CreatedAt  time.Time        `json:"created_at"`
-		TotalPrice float64          `json:"total_price"`
-		AccountID  string           `json:"account_id"`
-		Products   []OrderedProduct `json:"products"`
+		ID        string           `json:"id"`
+		CreatedAt time.Time        `json:"created_at"`
+		AccountID string           `json:"account_id"`
+		Products  []OrderedProduct `json:"products"`
+
+		// TotalPrice is Subtotal less the adjustments plus shipping and
+		// the taxes not included in the prices. TaxTotal covers all taxes.
+		Subtotal     money.Money `json:"subtotal"`
+		ShippingCost money.Money `json:"shipping_cost"`
+		TaxTotal     money.Money `json:"tax_total"`
+		TotalPrice   money.Money `json:"total_price"`
+
+		// The addresses are snapshots, later changes to the account's
+		// address book do not affect placed orders.
+		ShippingAddress address.Address `json:"shipping_address"`
+		BillingAddress  address.Address `json:"billing_address"`
+		ShippingMethod  string          `json:"shipping_method"`
+
+		Status        Status         `json:"status"`
+		StatusHistory []StatusChange `json:"status_history,omitempty"`
+
+		// ReservationID identifies the catalog stock reservation held
+		// for this order.
+		ReservationID string `json:"reservation_id,omitempty"`
+
+		Payment *Payment `json:"payment,omitempty"`
+
+		// Adjustments are the discounts taken off the sum of the products
+		// to get to TotalPrice.
+		Adjustments []promotion.Adjustment `json:"adjustments,omitempty"`
+
+		TaxLines []tax.TaxLine `json:"tax_lines,omitempty"`
+
+		// CouponCodes are the codes the order was placed with. The
+		// applied ones are recorded on the adjustments.
+		CouponCodes []string `json:"-"`
+
+		// IdempotencyKey is the optional client supplied key the order
+		// was placed with. It is not part of the stored order.
+		IdempotencyKey string `json:"-"`
 	}
 
+	// OrderedProduct is an order line. Lines for a variant of a product
+	// keep the variant's SKU and name, such as "M / Blue", next to the
+	// product's.
 	OrderedProduct struct {
-		ID          string  `json:"id"`
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		Price       float64 `json:"price"`
-		Quantity    uint32  `json:"quantity"`
+		ID          string      `json:"id"`
+		VariantID   string      `json:"variant_id,omitempty"`
+		SKU         string      `json:"sku,omitempty"`
+		VariantName string      `json:"variant_name,omitempty"`
+		Name        string      `json:"name"`
+		Description string      `json:"description"`
+		Price       money.Money `json:"price"`
+		TaxCategory string      `json:"tax_category"`
+		Quantity    uint32      `json:"quantity"`
 	}
 
 	orderService struct {
 		repository OrderRepository
+		payments   PaymentProvider
+		promotions promotion.PromotionRepository
+		taxes      tax.Calculator
+		shipping   ShippingRateCalculator
 	}
 )
 
-func NewOrderService(r OrderRepository) OrderService {
-	return &orderService{r}
+// Fingerprint identifies what was ordered, independent of line order.
+func (o Order) Fingerprint() string {
+	lines := []string{}
+	for _, p := range o.Products {
+		if p.VariantID != "" {
+			// The variant is enough, the product ID may not be given
+			lines = append(lines, fmt.Sprintf("variant:%s:%d", p.VariantID, p.Quantity))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s:%d", p.ID, p.Quantity))
+	}
+	sort.Strings(lines)
+
+	codes := []string{}
+	for _, c := range o.CouponCodes {
+		codes = append(codes, "coupon:"+strings.ToUpper(c))
+	}
+	sort.Strings(codes)
+
+	fields := []string{o.AccountID, o.ShippingMethod}
+	fields = append(fields, addressFields(o.ShippingAddress)...)
+	fields = append(fields, addressFields(o.BillingAddress)...)
+	return idempotency.Fingerprint(append(append(fields, lines...), codes...)...)
+}
+
+func addressFields(a address.Address) []string {
+	return []string{a.Name, a.Line1, a.Line2, a.City, a.Region, a.PostalCode, a.Country}
+}
+
+func NewOrderService(
+	r OrderRepository,
+	p PaymentProvider,
+	promotions promotion.PromotionRepository,
+	taxes tax.Calculator,
+	shipping ShippingRateCalculator,
+) OrderService {
+	return &orderService{r, p, promotions, taxes, shipping}
 }
 
+// placeAttempts bounds how often an order is priced again because a
+// promotion it was priced with reached its usage limit meanwhile.
+const placeAttempts = 3
+
 func (s *orderService) PostOrder(
 	ctx context.Context,
-	accountID string,
-	products []OrderedProduct,
+	o Order,
+) (*Order, error) {
+	// Pricing again leaves the used up automatic promotion out and fails
+	// for a used up coupon
+	for attempt := 1; ; attempt++ {
+		order, err := s.placeOrder(ctx, o)
+		if errors.Is(err, ErrPromotionLimitReached) && attempt < placeAttempts {
+			continue
+		}
+		return order, err
+	}
+}
+
+func (s *orderService) placeOrder(
+	ctx context.Context,
+	o Order,
 ) (*Order, error) {
-	totalPrice := 0.0
-	for _, p := range products {
-		totalPrice += p.Price * float64(p.Quantity)
+	subtotal := money.Zero(money.DefaultCurrency)
+	if len(o.Products) > 0 {
+		subtotal = money.Zero(o.Products[0].Price.Currency)
+	}
+	for _, p := range o.Products {
+		var err error
+		subtotal, err = subtotal.Add(p.Price.Mul(int64(p.Quantity)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	createdAt := time.Now()
+	adjustments, err := s.applyPromotions(ctx, o, subtotal, createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	discount := money.Zero(subtotal.Currency)
+	for _, a := range adjustments {
+		discount, err = discount.Add(a.Amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	taxLines, err := s.taxes.Calculate(ctx, o.ShippingAddress, taxableLines(o.Products, subtotal, discount))
+	if err != nil {
+		return nil, err
+	}
+
+	shippingMethod := o.ShippingMethod
+	if shippingMethod == "" {
+		shippingMethod = DefaultShippingMethod
+	}
+	shippingCost, err := s.shipping.Rate(ctx, shippingMethod, o.ShippingAddress, o.Products, subtotal)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPrice, err := subtotal.Sub(discount)
+	if err != nil {
+		return nil, err
+	}
+	totalPrice, err = totalPrice.Add(shippingCost)
+	if err != nil {
+		return nil, err
+	}
+	taxTotal := money.Zero(subtotal.Currency)
+	for _, t := range taxLines {
+		taxTotal, err = taxTotal.Add(t.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if !t.Inclusive {
+			totalPrice, err = totalPrice.Add(t.Amount)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	order := Order{
-		ID:         ksuid.New().String(),
-		CreatedAt:  time.Now(),
-		TotalPrice: totalPrice,
-		AccountID:  accountID,
-		Products:   products,
+		ID:              ksuid.New().String(),
+		CreatedAt:       createdAt,
+		AccountID:       o.AccountID,
+		Products:        o.Products,
+		Subtotal:        subtotal,
+		ShippingCost:    shippingCost,
+		TaxTotal:        taxTotal,
+		TotalPrice:      totalPrice,
+		ShippingAddress: o.ShippingAddress,
+		BillingAddress:  o.BillingAddress,
+		ShippingMethod:  shippingMethod,
+		Status:          StatusPending,
+		ReservationID:   o.ReservationID,
+		Adjustments:     adjustments,
+		TaxLines:        taxLines,
+		IdempotencyKey:  o.IdempotencyKey,
+		CouponCodes:     o.CouponCodes,
 	}
 
-	err := s.repository.PutOrder(ctx, order)
+	reference, err := s.payments.Authorize(ctx, order.ID, order.TotalPrice)
+	if err != nil {
+		return nil, err
+	}
+	order.Payment = &Payment{
+		ID:        ksuid.New().String(),
+		OrderID:   order.ID,
+		Reference: reference,
+		Amount:    order.TotalPrice,
+		Status:    PaymentAuthorized,
+		CreatedAt: order.CreatedAt,
+		UpdatedAt: order.CreatedAt,
+	}
+
+	err = s.repository.PutOrder(ctx, order)
+	if err != nil {
+		// The order was not placed, so nothing may stay authorized for it
+		if err := s.payments.Void(ctx, reference); err != nil {
+			log.Println("Error voiding payment: ", err)
+		}
+	}
+	if errors.Is(err, ErrIdempotencyKeyClaimed) {
+		// A concurrent request with the same key won the race
+		return s.GetIdempotentOrder(ctx, o)
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &order, nil
 }
 
+// applyPromotions evaluates the automatic promotions and the coupons of o
+// against its products.
+func (s *orderService) applyPromotions(
+	ctx context.Context,
+	o Order,
+	subtotal money.Money,
+	now time.Time,
+) ([]promotion.Adjustment, error) {
+	promotions, err := s.promotions.GetPromotions(ctx, now, o.CouponCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	uses, err := s.repository.CountPromotionUses(ctx, o.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []promotion.Line{}
+	for _, p := range o.Products {
+		lines = append(lines, promotion.Line{
+			ProductID: p.ID,
+			Price:     p.Price,
+			Quantity:  p.Quantity,
+		})
+	}
+
+	return promotion.Apply(promotions, promotion.Basket{
+		AccountID: o.AccountID,
+		Lines:     lines,
+		Subtotal:  subtotal,
+		Codes:     o.CouponCodes,
+		Now:       now,
+		Uses:      uses,
+	})
+}
+
+// taxableLines spreads the order discount over the products in proportion
+// to their amounts, the last line taking the rounding remainder.
+func taxableLines(
+	products []OrderedProduct,
+	subtotal money.Money,
+	discount money.Money,
+) []tax.Line {
+	lines := []tax.Line{}
+	remaining := discount.Amount
+	for i, p := range products {
+		amount := p.Price.Mul(int64(p.Quantity))
+
+		share := remaining
+		if i < len(products)-1 && subtotal.Amount > 0 {
+			share = discount.Amount * amount.Amount / subtotal.Amount
+		}
+		remaining -= share
+
+		lines = append(lines, tax.Line{
+			ProductID: p.ID,
+			Category:  p.TaxCategory,
+			Amount:    money.New(amount.Amount-share, amount.Currency),
+		})
+	}
+	return lines
+}
+
+// GetIdempotentOrder returns the order previously placed with the same
+// idempotency key, or nil if there is none. Reusing a key for a different
+// request fails with idempotency.ErrConflict.
+func (s *orderService) GetIdempotentOrder(
+	ctx context.Context,
+	o Order,
+) (*Order, error) {
+	if o.IdempotencyKey == "" {
+		return nil, nil
+	}
+
+	orderID, fingerprint, err := s.repository.GetIdempotencyKey(ctx, o.AccountID, o.IdempotencyKey)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if fingerprint != o.Fingerprint() {
+		return nil, idempotency.ErrConflict
+	}
+
+	return s.repository.GetOrderByID(ctx, orderID)
+}
+
+func (s *orderService) GetOrder(
+	ctx context.Context,
+	id string,
+) (*Order, error) {
+	return s.repository.GetOrderByID(ctx, id)
+}
+
 func (s *orderService) GetOrdersForAccount(
 	ctx context.Context,
 	accountId string,
 ) ([]Order, error) {
 	return s.repository.GetOrdersForAccount(ctx, accountId)
 }
+
+func (s *orderService) UpdateOrderStatus(
+	ctx context.Context,
+	id string,
+	status Status,
+) (*Order, error) {
+	order, err := s.repository.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !order.Status.CanTransitionTo(status) {
+		return nil, &InvalidTransitionError{From: order.Status, To: status}
+	}
+
+	// The payment is settled while the order is locked in its current
+	// status, so a concurrent transition cannot leave the money moved and
+	// the order where it was
+	changedAt := time.Now()
+	err = s.repository.UpdateOrderStatus(ctx, id, order.Status, status, changedAt, func() (*Payment, error) {
+		if order.Payment == nil {
+			return nil, nil
+		}
+		settled, err := order.Payment.settle(ctx, s.payments, status)
+		if err != nil || !settled {
+			return nil, err
+		}
+		return order.Payment, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order.Status = status
+	order.StatusHistory = append(order.StatusHistory, StatusChange{
+		Status:    status,
+		ChangedAt: changedAt,
+	})
+	return order, nil
+}
+
+func (s *orderService) CancelOrder(
+	ctx context.Context,
+	id string,
+) (*Order, error) {
+	return s.UpdateOrderStatus(ctx, id, StatusCancelled)
+}
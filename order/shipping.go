@@ -0,0 +1,74 @@
+package order
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+)
+
+const DefaultShippingMethod = "standard"
+
+var (
+	ErrUnknownShippingMethod = errors.New("unknown shipping method")
+)
+
+type (
+	// ShippingRateCalculator prices shipping the products of an order to
+	// an address with a shipping method.
+	ShippingRateCalculator interface {
+		Rate(
+			ctx context.Context,
+			method string,
+			to address.Address,
+			products []OrderedProduct,
+			subtotal money.Money,
+		) (money.Money, error)
+	}
+
+	// FlatRate is a fixed charge plus a charge per item, in minor units of
+	// the order currency. Orders reaching FreeFrom, when set, ship free.
+	FlatRate struct {
+		Base     int64
+		PerItem  int64
+		FreeFrom int64
+	}
+
+	FlatRateCalculator struct {
+		rates map[string]FlatRate
+	}
+)
+
+var DefaultFlatRates = map[string]FlatRate{
+	"standard": {Base: 499, FreeFrom: 5000},
+	"express":  {Base: 1499, PerItem: 100},
+	"pickup":   {},
+}
+
+func NewFlatRateCalculator(rates map[string]FlatRate) *FlatRateCalculator {
+	return &FlatRateCalculator{rates}
+}
+
+func (c *FlatRateCalculator) Rate(
+	_ context.Context,
+	method string,
+	_ address.Address,
+	products []OrderedProduct,
+	subtotal money.Money,
+) (money.Money, error) {
+	rate, ok := c.rates[method]
+	if !ok {
+		return money.Money{}, ErrUnknownShippingMethod
+	}
+
+	if rate.FreeFrom > 0 && subtotal.Amount >= rate.FreeFrom {
+		return money.Zero(subtotal.Currency), nil
+	}
+
+	items := int64(0)
+	for _, p := range products {
+		items += int64(p.Quantity)
+	}
+	return money.New(rate.Base+rate.PerItem*items, subtotal.Currency), nil
+}
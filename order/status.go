@@ -0,0 +1,95 @@
+package order
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/stiffinWanjohi/go-ecommerce/order/pb"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusPaid      Status = "paid"
+	StatusFulfilled Status = "fulfilled"
+	StatusShipped   Status = "shipped"
+	StatusDelivered Status = "delivered"
+	StatusCancelled Status = "cancelled"
+	StatusRefunded  Status = "refunded"
+)
+
+// transitions lists, for every status, the statuses an order may move to
+// next. Cancelled and refunded orders are final.
+var transitions = map[Status][]Status{
+	StatusPending:   {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusFulfilled, StatusCancelled, StatusRefunded},
+	StatusFulfilled: {StatusShipped, StatusRefunded},
+	StatusShipped:   {StatusDelivered, StatusRefunded},
+	StatusDelivered: {StatusRefunded},
+	StatusCancelled: {},
+	StatusRefunded:  {},
+}
+
+type (
+	StatusChange struct {
+		Status    Status    `json:"status"`
+		ChangedAt time.Time `json:"changed_at"`
+	}
+
+	InvalidTransitionError struct {
+		From Status
+		To   Status
+	}
+)
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("order cannot move from %s to %s", e.From, e.To)
+}
+
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, t := range transitions[s] {
+		if t == next {
+			return true
+		}
+	}
+	return false
+}
+
+var statusToProto = map[Status]pb.OrderStatus{
+	StatusPending:   pb.OrderStatus_ORDER_STATUS_PENDING,
+	StatusPaid:      pb.OrderStatus_ORDER_STATUS_PAID,
+	StatusFulfilled: pb.OrderStatus_ORDER_STATUS_FULFILLED,
+	StatusShipped:   pb.OrderStatus_ORDER_STATUS_SHIPPED,
+	StatusDelivered: pb.OrderStatus_ORDER_STATUS_DELIVERED,
+	StatusCancelled: pb.OrderStatus_ORDER_STATUS_CANCELLED,
+	StatusRefunded:  pb.OrderStatus_ORDER_STATUS_REFUNDED,
+}
+
+// HasBeen reports whether the order was in status at some point.
+func (o Order) HasBeen(status Status) bool {
+	for _, c := range o.StatusHistory {
+		if c.Status == status {
+			return true
+		}
+	}
+	return o.Status == status
+}
+
+func (s Status) Proto() pb.OrderStatus {
+	return statusToProto[s]
+}
+
+func StatusFromProto(p pb.OrderStatus) Status {
+	for s, sp := range statusToProto {
+		if sp == p {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package order
+
+import (
+	"context"
+	"log"
+	"time"
+
+	catalog "github.com/stiffinWanjohi/go-ecommerce/catalog"
+)
+
+// ReservationSweeper settles the catalog stock reservations that expired
+// unconfirmed. Those an order was placed with are confirmed. The others
+// were left behind by orders that failed or crashed before being stored,
+// and their stock is released.
+//
+// Reservations expire well after any order placement finishes, see
+// catalog.ReservationTTL, so no order can still be placed with an expired
+// one.
+type ReservationSweeper struct {
+	repository OrderRepository
+	catalog    *catalog.Client
+	interval   time.Duration
+	batchSize  uint64
+}
+
+func NewReservationSweeper(r OrderRepository, c *catalog.Client, interval time.Duration) *ReservationSweeper {
+	return &ReservationSweeper{
+		repository: r,
+		catalog:    c,
+		interval:   interval,
+		batchSize:  100,
+	}
+}
+
+// Run sweeps reservations until ctx is cancelled.
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *ReservationSweeper) sweep(ctx context.Context) {
+	for {
+		ids, err := s.catalog.GetExpiredReservations(ctx, s.batchSize)
+		if err != nil {
+			log.Println("Error getting expired reservations: ", err)
+			return
+		}
+		if len(ids) == 0 {
+			return
+		}
+
+		placed, err := s.repository.GetPlacedReservations(ctx, ids)
+		if err != nil {
+			log.Println("Error getting placed reservations: ", err)
+			return
+		}
+
+		// Leave failed reservations to the next run
+		failed := false
+		for _, id := range ids {
+			if placed[id] {
+				err = s.catalog.ConfirmReservation(ctx, id)
+			} else {
+				err = s.catalog.ReleaseStock(ctx, id)
+			}
+			if err != nil {
+				log.Printf("Error sweeping stock reservation %s: %v", id, err)
+				failed = true
+			}
+		}
+
+		if failed || uint64(len(ids)) < s.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package promotion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+)
+
+type Kind string
+
+const (
+	KindPercentage Kind = "percentage"
+	KindFixed      Kind = "fixed"
+	KindBuyXGetY   Kind = "buy_x_get_y"
+)
+
+type (
+	// Promotion is a discount rule. Promotions without a code apply
+	// automatically, coupons only when their code is given.
+	Promotion struct {
+		ID          string `json:"id"`
+		Code        string `json:"code,omitempty"`
+		Description string `json:"description"`
+		Kind        Kind   `json:"kind"`
+
+		// PercentOff is used by percentage promotions, 1 to 100.
+		PercentOff uint32 `json:"percent_off,omitempty"`
+
+		// AmountOff is used by fixed amount promotions.
+		AmountOff money.Money `json:"amount_off"`
+
+		// Buy-X-get-Y promotions give GetQuantity of ProductID for free
+		// for every BuyQuantity bought.
+		ProductID   string `json:"product_id,omitempty"`
+		BuyQuantity uint32 `json:"buy_quantity,omitempty"`
+		GetQuantity uint32 `json:"get_quantity,omitempty"`
+
+		// MinBasket is the subtotal the basket must reach, if set.
+		MinBasket money.Money `json:"min_basket"`
+
+		// PerAccountLimit caps how often one account may use the
+		// promotion. Zero means unlimited.
+		PerAccountLimit uint32 `json:"per_account_limit,omitempty"`
+
+		StartsAt time.Time `json:"starts_at"`
+		// EndsAt is the end of the validity window, open if zero.
+		EndsAt time.Time `json:"ends_at"`
+	}
+
+	Line struct {
+		ProductID string
+		Price     money.Money
+		Quantity  uint32
+	}
+
+	// Basket is what a promotion is evaluated against.
+	Basket struct {
+		AccountID string
+		Lines     []Line
+		Subtotal  money.Money
+		Codes     []string
+		Now       time.Time
+
+		// Uses holds how often the account already used each promotion,
+		// by promotion ID.
+		Uses map[string]int
+	}
+
+	// Adjustment is a discount applied to an order. Amount is what was
+	// taken off the subtotal.
+	Adjustment struct {
+		PromotionID string      `json:"promotion_id"`
+		Code        string      `json:"code,omitempty"`
+		Description string      `json:"description"`
+		Amount      money.Money `json:"amount"`
+	}
+
+	// CodeError explains why a coupon code that was given cannot be used.
+	CodeError struct {
+		Code   string
+		Reason string
+	}
+)
+
+func (e *CodeError) Error() string {
+	return fmt.Sprintf("coupon %s cannot be applied: %s", e.Code, e.Reason)
+}
+
+// Apply evaluates promotions against the basket and returns the discounts
+// to apply, in the order given. Automatic promotions that do not qualify
+// are skipped, while any given coupon code that does not qualify fails
+// with a CodeError. The discounts never add up to more than the subtotal.
+func Apply(promotions []Promotion, b Basket) ([]Adjustment, error) {
+	byCode := map[string]Promotion{}
+	for _, p := range promotions {
+		if p.Code != "" {
+			byCode[strings.ToUpper(p.Code)] = p
+		}
+	}
+
+	applicable := []Promotion{}
+	for _, p := range promotions {
+		if p.Code == "" && p.reason(b) == "" {
+			applicable = append(applicable, p)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, code := range b.Codes {
+		code = strings.ToUpper(code)
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		p, ok := byCode[code]
+		if !ok {
+			return nil, &CodeError{Code: code, Reason: "unknown code"}
+		}
+		if reason := p.reason(b); reason != "" {
+			return nil, &CodeError{Code: code, Reason: reason}
+		}
+		applicable = append(applicable, p)
+	}
+
+	adjustments := []Adjustment{}
+	remaining := b.Subtotal.Amount
+	for _, p := range applicable {
+		amount := p.discount(b)
+		if amount > remaining {
+			amount = remaining
+		}
+		if amount <= 0 {
+			continue
+		}
+		remaining -= amount
+
+		adjustments = append(adjustments, Adjustment{
+			PromotionID: p.ID,
+			Code:        p.Code,
+			Description: p.Description,
+			Amount:      money.New(amount, b.Subtotal.Currency),
+		})
+	}
+
+	return adjustments, nil
+}
+
+// reason tells why the promotion does not apply to the basket, or returns
+// an empty string if it does.
+func (p Promotion) reason(b Basket) string {
+	switch {
+	case b.Now.Before(p.StartsAt):
+		return "not active yet"
+	case !p.EndsAt.IsZero() && !b.Now.Before(p.EndsAt):
+		return "expired"
+	case p.PerAccountLimit > 0 && b.Uses[p.ID] >= int(p.PerAccountLimit):
+		return "usage limit reached"
+	}
+
+	if !p.MinBasket.IsZero() {
+		if p.MinBasket.Currency != b.Subtotal.Currency {
+			return "not available in " + b.Subtotal.Currency
+		}
+		if b.Subtotal.Amount < p.MinBasket.Amount {
+			return fmt.Sprintf("basket must be at least %s", p.MinBasket)
+		}
+	}
+
+	switch p.Kind {
+	case KindFixed:
+		if p.AmountOff.Currency != b.Subtotal.Currency {
+			return "not available in " + b.Subtotal.Currency
+		}
+	case KindBuyXGetY:
+		if p.discount(b) == 0 {
+			return fmt.Sprintf("requires %d of product %s", p.BuyQuantity+p.GetQuantity, p.ProductID)
+		}
+	}
+
+	return ""
+}
+
+// discount is the amount, in minor units of the basket currency, the
+// promotion takes off before capping.
+func (p Promotion) discount(b Basket) int64 {
+	switch p.Kind {
+	case KindPercentage:
+		return b.Subtotal.Amount * int64(p.PercentOff) / 100
+	case KindFixed:
+		return p.AmountOff.Amount
+	case KindBuyXGetY:
+		if p.BuyQuantity == 0 || p.GetQuantity == 0 {
+			return 0
+		}
+		// Variants of the product are separate lines, possibly at different
+		// prices. They count together and the cheapest units are free.
+		lines := []Line{}
+		var quantity uint32
+		for _, l := range b.Lines {
+			if l.ProductID == p.ProductID {
+				lines = append(lines, l)
+				quantity += l.Quantity
+			}
+		}
+		sort.SliceStable(lines, func(i, j int) bool {
+			return lines[i].Price.Amount < lines[j].Price.Amount
+		})
+
+		free := quantity / (p.BuyQuantity + p.GetQuantity) * p.GetQuantity
+		var amount int64
+		for _, l := range lines {
+			n := min(free, l.Quantity)
+			amount += l.Price.Mul(int64(n)).Amount
+			free -= n
+		}
+		return amount
+	}
+	return 0
+}
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS promotions (
+    id CHAR(27) PRIMARY KEY,
+    code VARCHAR(64) NOT NULL DEFAULT '',
+    description TEXT NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    percent_off INT NOT NULL DEFAULT 0,
+    amount_off BIGINT NOT NULL DEFAULT 0,
+    product_id VARCHAR(27) NOT NULL DEFAULT '',
+    buy_quantity INT NOT NULL DEFAULT 0,
+    get_quantity INT NOT NULL DEFAULT 0,
+    min_basket BIGINT NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    per_account_limit INT NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS promotions_code_idx ON promotions (UPPER(code)) WHERE code <> '';
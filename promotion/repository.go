@@ -0,0 +1,120 @@
+package promotion
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+)
+
+type (
+	PromotionRepository interface {
+		Close()
+		// GetPromotions returns the automatic promotions active at now
+		// and the coupons matching codes, whether active or not.
+		GetPromotions(ctx context.Context, now time.Time, codes []string) ([]Promotion, error)
+	}
+
+	postgresRepository struct {
+		db *sql.DB
+	}
+)
+
+func NewPostgresRepository(url string) (PromotionRepository, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+
+	return &postgresRepository{db}, nil
+}
+
+func (r *postgresRepository) Close() {
+	r.db.Close()
+}
+
+func (r *postgresRepository) GetPromotions(
+	ctx context.Context,
+	now time.Time,
+	codes []string,
+) ([]Promotion, error) {
+	upper := []string{}
+	for _, c := range codes {
+		upper = append(upper, strings.ToUpper(c))
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT
+		id,
+		code,
+		description,
+		kind,
+		percent_off,
+		amount_off,
+		product_id,
+		buy_quantity,
+		get_quantity,
+		min_basket,
+		currency,
+		per_account_limit,
+		starts_at,
+		ends_at
+		FROM promotions
+		WHERE (code = '' AND starts_at <= $1 AND (ends_at IS NULL OR ends_at > $1))
+		OR UPPER(code) = ANY($2)
+		ORDER BY id`,
+		now, pq.Array(upper),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := []Promotion{}
+	for rows.Next() {
+		var (
+			p         Promotion
+			amountOff int64
+			minBasket int64
+			currency  string
+			endsAt    sql.NullTime
+		)
+		if err := rows.Scan(
+			&p.ID,
+			&p.Code,
+			&p.Description,
+			&p.Kind,
+			&p.PercentOff,
+			&amountOff,
+			&p.ProductID,
+			&p.BuyQuantity,
+			&p.GetQuantity,
+			&minBasket,
+			&currency,
+			&p.PerAccountLimit,
+			&p.StartsAt,
+			&endsAt,
+		); err != nil {
+			return nil, err
+		}
+		p.AmountOff = money.New(amountOff, currency)
+		p.MinBasket = money.New(minBasket, currency)
+		p.EndsAt = endsAt.Time
+		promotions = append(promotions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
@@ -0,0 +1,128 @@
+package tax
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+)
+
+type (
+	// Rule is the tax rate of a country, optionally narrowed down to a
+	// region and a product tax category.
+	Rule struct {
+		Country   string `json:"country"`
+		Region    string `json:"region,omitempty"`
+		Category  string `json:"category,omitempty"`
+		Rate      string `json:"rate"`
+		Inclusive bool   `json:"inclusive"`
+
+		rate Rate
+	}
+
+	// TableCalculator looks the rate of every line up in a table of rules.
+	// The most specific matching rule wins, a region match weighing more
+	// than a category match. Lines no rule matches are not taxed.
+	TableCalculator struct {
+		rules []Rule
+	}
+
+	tableConfig struct {
+		Rules []Rule `json:"rules"`
+	}
+)
+
+func NewTableCalculator(rules []Rule) (*TableCalculator, error) {
+	c := &TableCalculator{}
+	for _, r := range rules {
+		rate, err := ParseRate(r.Rate)
+		if err != nil {
+			return nil, err
+		}
+		r.rate = rate
+		r.Country = strings.ToUpper(r.Country)
+		r.Region = strings.ToUpper(r.Region)
+		c.rules = append(c.rules, r)
+	}
+	return c, nil
+}
+
+// LoadTableCalculator reads the rules from a JSON file of the form
+// {"rules": [{"country": "US", "region": "CA", "rate": "7.25"}]}.
+func LoadTableCalculator(path string) (*TableCalculator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg tableConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	return NewTableCalculator(cfg.Rules)
+}
+
+func (c *TableCalculator) Calculate(
+	_ context.Context,
+	to address.Address,
+	lines []Line,
+) ([]TaxLine, error) {
+	to = to.Normalize()
+
+	taxLines := []TaxLine{}
+	for _, l := range lines {
+		category := l.Category
+		if category == "" {
+			category = DefaultCategory
+		}
+
+		rule := c.match(to, category)
+		if rule == nil {
+			continue
+		}
+
+		jurisdiction := rule.Country
+		if rule.Region != "" {
+			jurisdiction += "-" + rule.Region
+		}
+		taxLines = append(taxLines, TaxLine{
+			ProductID:    l.ProductID,
+			Category:     category,
+			Jurisdiction: jurisdiction,
+			Rate:         rule.rate,
+			Inclusive:    rule.Inclusive,
+			Amount:       rule.rate.Of(l.Amount, rule.Inclusive),
+		})
+	}
+
+	return taxLines, nil
+}
+
+func (c *TableCalculator) match(to address.Address, category string) *Rule {
+	var (
+		best      *Rule
+		bestScore = -1
+	)
+	for i, r := range c.rules {
+		if r.Country != to.Country ||
+			(r.Region != "" && r.Region != to.Region) ||
+			(r.Category != "" && r.Category != category) {
+			continue
+		}
+
+		score := 0
+		if r.Region != "" {
+			score += 2
+		}
+		if r.Category != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = &c.rules[i], score
+		}
+	}
+	return best
+}
@@ -0,0 +1,77 @@
+package tax
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/stiffinWanjohi/go-ecommerce/address"
+	"github.com/stiffinWanjohi/go-ecommerce/money"
+)
+
+// DefaultCategory is the tax category of products that do not name one.
+const DefaultCategory = "standard"
+
+// Rate is a tax rate in millionths, e.g. 72500 is 7.25%.
+type Rate int64
+
+type (
+	// Calculator works out the taxes due on the lines of an order
+	// shipped to an address.
+	Calculator interface {
+		Calculate(ctx context.Context, to address.Address, lines []Line) ([]TaxLine, error)
+	}
+
+	// Line is a taxable amount, after discounts, of one product.
+	Line struct {
+		ProductID string
+		Category  string
+		Amount    money.Money
+	}
+
+	// TaxLine is the tax on one line. Inclusive taxes are already part of
+	// the line amount, exclusive ones come on top of it.
+	TaxLine struct {
+		ProductID    string      `json:"product_id"`
+		Category     string      `json:"category"`
+		Jurisdiction string      `json:"jurisdiction"`
+		Rate         Rate        `json:"rate"`
+		Inclusive    bool        `json:"inclusive"`
+		Amount       money.Money `json:"amount"`
+	}
+)
+
+// ParseRate parses a percentage such as "7.25".
+func ParseRate(percent string) (Rate, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(percent))
+	if !ok || r.Sign() < 0 {
+		return 0, fmt.Errorf("invalid tax rate %q", percent)
+	}
+
+	r.Mul(r, big.NewRat(10000, 1))
+	if !r.IsInt() {
+		return 0, fmt.Errorf("tax rate %q has too many decimals", percent)
+	}
+	return Rate(r.Num().Int64()), nil
+}
+
+// String formats the rate as a percentage without trailing zeros.
+func (r Rate) String() string {
+	s := big.NewRat(int64(r), 10000).FloatString(4)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// Of returns the tax on amount, rounded half up to the minor unit.
+func (r Rate) Of(amount money.Money, inclusive bool) money.Money {
+	num := new(big.Int).Mul(big.NewInt(amount.Amount), big.NewInt(int64(r)))
+	den := big.NewInt(1000000)
+	if inclusive {
+		den.Add(den, big.NewInt(int64(r)))
+	}
+
+	// (2*num + den) / (2*den) rounds half up for non-negative amounts
+	num.Mul(num, big.NewInt(2)).Add(num, den)
+	den.Mul(den, big.NewInt(2))
+	return money.New(num.Quo(num, den).Int64(), amount.Currency)
+}
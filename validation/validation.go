@@ -0,0 +1,73 @@
+// Package validation collects what is wrong with a request, field by field.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type (
+	// FieldViolation describes a problem with one field. Field is the
+	// path of the field in the request, such as "products[1].quantity".
+	FieldViolation struct {
+		Field       string `json:"field"`
+		Description string `json:"description"`
+	}
+
+	Error struct {
+		Violations []FieldViolation
+	}
+)
+
+func (e *Error) Error() string {
+	messages := []string{}
+	for _, v := range e.Violations {
+		messages = append(messages, v.Field+" "+v.Description)
+	}
+	return "invalid request: " + strings.Join(messages, "; ")
+}
+
+// Check records a violation of field unless ok holds.
+func (e *Error) Check(ok bool, field string, format string, args ...interface{}) {
+	if !ok {
+		e.Violations = append(e.Violations, FieldViolation{
+			Field:       field,
+			Description: fmt.Sprintf(format, args...),
+		})
+	}
+}
+
+// Merge records the violations of err, a nested validation error, under
+// prefix. Any other error is recorded as a violation of prefix itself.
+func (e *Error) Merge(prefix string, err error) {
+	if err == nil {
+		return
+	}
+
+	var nested *Error
+	if !errors.As(err, &nested) {
+		e.Violations = append(e.Violations, FieldViolation{Field: prefix, Description: err.Error()})
+		return
+	}
+	for _, v := range nested.Violations {
+		if prefix != "" {
+			v.Field = prefix + "." + v.Field
+		}
+		e.Violations = append(e.Violations, v)
+	}
+}
+
+// Err returns e if any violation was recorded, nil otherwise.
+func (e *Error) Err() error {
+	if len(e.Violations) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Length reports whether s is between min and max characters long.
+func Length(s string, min int, max int) bool {
+	n := len([]rune(strings.TrimSpace(s)))
+	return n >= min && n <= max
+}